@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/euank/ifacepropagate/pkg/ifacepropagate"
@@ -14,7 +17,7 @@ import (
 
 func usage() {
 	fmt.Fprintf(os.Stderr, `Usage:
-  ifacepropagate [package] [struct] [interfaces] > out_generated.go
+  ifacepropagate [flags] [package] [struct] [interfaces] > out_generated.go
 
 ifacepropagate generates code to allow 'propagating' interface implementations
 up from an embedded interface.
@@ -35,42 +38,291 @@ ARGS:
   struct      A specifier for the struct that contains an embedded interface
                which we're wrapping. For example "s *MyStruct.Conn" if the
               struct is named 'MyStruct', has a pointer receiver, and is
-              embedding a 'net.Conn' interface.
+              embedding a 'net.Conn' interface. The interface may also be
+              held by a named field instead, such as "s *MyStruct.inner",
+              or be reached through other struct fields, such as
+              "s *MyStruct.base.Conn". Generic structs name their type
+              parameters, such as "s *MyStruct[K, V].Store".
 
   interfaces  The list of interfaces to "propagate" up, comma separated.
               For example 'syscall.Conn,io.Reader,net.Conn'.
+              Generic interfaces must be instantiated, such as
+              'example.com/cache.Invalidator[string]'. Interface literals,
+              such as 'interface{ CloseWrite() error }', work too.
+
+FLAGS:
+  -methods    Propagate individual methods instead of interfaces. Each entry
+              in [interfaces] is then a method of some type or interface,
+              such as '*net.TCPConn.SetReadBuffer,net/http.Flusher.Flush',
+              which is propagated on its own.
+
+  -types      Propagate whatever methods the types in [interfaces] have
+              beyond the embedded interface, such as
+              '*net.TCPConn,*crypto/tls.Conn'. Methods are grouped by which
+              of the types have them.
+
+  -exclude    A comma separated list of methods not to propagate with
+              -types, such as 'SetLinger,File'.
+
+  -implies    A rule such as 'net/http.Pusher=>net/http.Flusher', saying
+              that any value implementing the first interface implements the
+              second one too. Cases for values which don't are left out, and
+              such values only get the embedded interface. May be repeated.
+
+  -realized-by
+              A comma separated list of the types the embedded interface
+              ever holds, such as '*net.TCPConn,*crypto/tls.Conn'. Only the
+              combinations of interfaces those implement get a case.
+
+  -fallback   What values get if they implement a combination of interfaces
+              left out due to -implies or -realized-by: 'base' for just the
+              embedded interface, which is the default, or 'nearest' for the
+              largest combination with a case, out of the ones they do
+              implement.
+
+  -max-cases  The most cases to generate, 256 by default. Each case is a
+              struct type of its own, so many of them slow down compiling
+              and bloat binaries. 0 means there is no maximum, as before this
+              flag was added; with the default, propagating 9 or more
+              interfaces fails unless some of their combinations are left out.
+
+  -no-verify  Don't type-check the generated code along with the rest of the
+              package before printing it. Only needed for packages which
+              go/types can't check on its own, such as ones using cgo.
+
+EXIT CODES:
+  1           The code could not be generated, e.g. because an interface
+              can't be propagated.
+  2           The arguments are invalid, e.g. the struct selector or an
+              interface literal is malformed.
+  3           The package, or one the interfaces refer to, could not be
+              loaded, e.g. because it has syntax errors.
 
 
 `)
 }
 
+const (
+	exitGenerate = 1
+	exitUsage    = 2
+	exitLoad     = 3
+)
+
 func main() {
-	args := os.Args
+	methods := flag.Bool("methods", false, "")
+	types := flag.Bool("types", false, "")
+	exclude := flag.String("exclude", "", "")
+	noVerify := flag.Bool("no-verify", false, "")
+	var implies impliesFlag
+	flag.Var(&implies, "implies", "")
+	realizedBy := flag.String("realized-by", "", "")
+	fallback := flag.String("fallback", "base", "")
+	maxCases := flag.Int("max-cases", 256, "")
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
 
-	if len(args) != 4 {
+	if len(args) != 3 {
 		usage()
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+	pkgSel, ifaceSel, ifacesList := args[0], args[1], args[2]
+	ifaces := splitInterfaces(ifacesList)
+
+	report := &ifacepropagate.Report{}
+	opts := []ifacepropagate.Option{ifacepropagate.ReportTo(report), ifacepropagate.MaxCases(*maxCases)}
+	if *methods {
+		opts = append(opts, ifacepropagate.PropagateMethods())
+	}
+	if *types {
+		opts = append(opts, ifacepropagate.PropagateTypes())
+	}
+	if *exclude != "" {
+		opts = append(opts, ifacepropagate.ExcludeMethods(strings.Split(*exclude, ",")...))
+	}
+	if *noVerify {
+		opts = append(opts, ifacepropagate.SkipVerify())
+	}
+	for _, imp := range implies {
+		opts = append(opts, ifacepropagate.Implies(imp[0], imp[1]))
+	}
+	if *realizedBy != "" {
+		opts = append(opts, ifacepropagate.RealizedBy(splitInterfaces(*realizedBy)...))
+	}
+	switch *fallback {
+	case "base":
+	case "nearest":
+		opts = append(opts, ifacepropagate.FallbackTo(ifacepropagate.FallbackNearest))
+	default:
+		fmt.Fprintf(os.Stderr, "invalid value %q for flag -fallback: must be 'base' or 'nearest'\n", *fallback)
+		usage()
+		os.Exit(exitUsage)
 	}
-	pkgSel, ifaceSel, ifacesList := args[1], args[2], args[3]
-	ifaces := strings.Split(ifacesList, ",")
 
 	pkgs, err := packages.Load(&packages.Config{
 		Mode: packages.NeedTypes | packages.NeedName | packages.NeedSyntax | packages.NeedImports,
 	}, pkgSel)
 	if err != nil {
-		log.Fatalf("error loading pkg %q: %v", pkgSel, err)
+		fmt.Fprintf(os.Stderr, "ifacepropagate: error loading package %q: %v\n", pkgSel, err)
+		os.Exit(exitLoad)
 	}
 	if len(pkgs) != 1 {
-		log.Fatalf("multiple packages found, but we needed to load only one package: %v", pkgs)
+		fmt.Fprintf(os.Stderr, "ifacepropagate: %q matches %d packages, but we need exactly one\n", pkgSel, len(pkgs))
+		os.Exit(exitLoad)
 	}
 	pkg := pkgs[0]
 
+	// Type errors are expected while the code we generate is missing or
+	// stale, so they only matter if generating fails, as a likely cause.
+	fatal, typeErrs := loadErrors(pkgs)
+	if len(fatal) > 0 {
+		for _, e := range fatal {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		fmt.Fprintf(os.Stderr, "ifacepropagate: package %q could not be loaded\n", pkgSel)
+		os.Exit(exitLoad)
+	}
+
 	ret, err := ifacepropagate.PropogateInterfaces(
-		pkg, "propagateInterfaces", ifaceSel, ifaces,
+		pkg, "propagateInterfaces", ifaceSel, ifaces, opts...,
 	)
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "ifacepropagate: %v\n", err)
+		var argErr *ifacepropagate.ArgumentError
+		if errors.As(err, &argErr) {
+			os.Exit(exitUsage)
+		}
+		if len(typeErrs) > 0 {
+			fmt.Fprintf(os.Stderr, "ifacepropagate: package %q has type errors, which may be why:\n", pkgSel)
+			for _, e := range typeErrs {
+				fmt.Fprintln(os.Stderr, e)
+			}
+		}
+		var loadErr *ifacepropagate.PackageLoadError
+		if errors.As(err, &loadErr) {
+			os.Exit(exitLoad)
+		}
+		os.Exit(exitGenerate)
+	}
+	for _, p := range report.Pruned {
+		fmt.Fprintf(os.Stderr, "ifacepropagate: left out %v, since %v has all of its methods\n", p.Interface, p.SubsumedBy)
 	}
+	reportLeftOut(report.SkippedCases, "no value can implement")
+	reportLeftOut(report.ImpliedCases, "the implications rule out")
+	reportLeftOut(report.UnrealizedCases, "none of the types implement")
+	cases := "cases"
+	if report.Cases == 1 {
+		cases = "case"
+	}
+	fmt.Fprintf(os.Stderr, "ifacepropagate: generated %d %s in %d lines\n", report.Cases, cases, report.Lines)
 	fmt.Println(ret)
 	os.Exit(0)
 }
+
+// reportLeftOut tells about the n combinations of interfaces which got no
+// case, for the given reason.
+func reportLeftOut(n int, why string) {
+	switch {
+	case n == 1:
+		fmt.Fprintf(os.Stderr, "ifacepropagate: left out 1 combination of interfaces %s\n", why)
+	case n > 1:
+		fmt.Fprintf(os.Stderr, "ifacepropagate: left out %d combinations of interfaces %s\n", n, why)
+	}
+}
+
+// loadErrors returns the errors packages.Load reported for the packages and
+// their dependencies. Errors which keep us from loading them at all are
+// returned separately from type errors, less any for the code we generate,
+// which is about to be replaced.
+func loadErrors(pkgs []*packages.Package) (fatal []packages.Error, typeErrs []packages.Error) {
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			if isGeneratedFile(errorFile(e.Pos)) {
+				continue
+			}
+			if e.Kind == packages.TypeError {
+				// Ones without a position are about the package as a whole,
+				// i.e. the output file's package clause still missing.
+				if e.Pos == "" || e.Pos == "-" {
+					continue
+				}
+				typeErrs = append(typeErrs, e)
+				continue
+			}
+			if e.Kind == packages.ListError && strings.HasPrefix(e.Msg, "# ") {
+				// The compiler's output for type errors, which we have as
+				// TypeErrors already
+				continue
+			}
+			fatal = append(fatal, e)
+		}
+	})
+	return fatal, typeErrs
+}
+
+// isGeneratedFile reports whether the file is one we generated, or empty,
+// as it is while a shell redirect is about to write our output to it.
+func isGeneratedFile(path string) bool {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return len(bytes.TrimSpace(src)) == 0 || ifacepropagate.IsGenerated(src)
+}
+
+// errorFile returns the file of a 'file:line:col' position.
+func errorFile(pos string) string {
+	for i := 0; i < 2; i++ {
+		colon := strings.LastIndex(pos, ":")
+		if colon == -1 {
+			break
+		}
+		if _, err := strconv.Atoi(pos[colon+1:]); err != nil {
+			break
+		}
+		pos = pos[:colon]
+	}
+	return pos
+}
+
+// impliesFlag collects the '-implies A=>B' rules.
+type impliesFlag [][2]string
+
+func (f *impliesFlag) String() string {
+	rules := make([]string, 0, len(*f))
+	for _, imp := range *f {
+		rules = append(rules, imp[0]+"=>"+imp[1])
+	}
+	return strings.Join(rules, ",")
+}
+
+func (f *impliesFlag) Set(v string) error {
+	parts := strings.SplitN(v, "=>", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return fmt.Errorf("%q must be of the form 'A=>B'", v)
+	}
+	*f = append(*f, [2]string{parts[0], parts[1]})
+	return nil
+}
+
+// splitInterfaces splits the comma separated list of interfaces, leaving the
+// commas in type argument lists, such as 'Pair[K, V]', and interface literals
+// alone.
+func splitInterfaces(list string) []string {
+	ret := []string{}
+	depth, start := 0, 0
+	for i, c := range list {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				ret = append(ret, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, list[start:])
+}
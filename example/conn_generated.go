@@ -43,6 +43,6 @@ func (l *closeLoggedConn) propagateInterfaces() net.Conn {
 func (l *closeLoggedConn) ReadFrom(r io.Reader) (n int64, err error) {
 	return l.Conn.(io.ReaderFrom).ReadFrom(r)
 }
-func (l *closeLoggedConn) SyscallConn() ( syscall.RawConn,  error) {
+func (l *closeLoggedConn) SyscallConn() (syscall.RawConn, error) {
 	return l.Conn.(ifacepropagateIfaceAlias0).SyscallConn()
 }
@@ -0,0 +1,60 @@
+package ifacepropagate
+
+import "math/bits"
+
+// maxInterfaces is the most interfaces we can propagate at once, since each
+// combination of them is a bitmask in an int, as is their number.
+const maxInterfaces = bits.UintSize - 2
+
+// estimateLines estimates how many lines the code for the given cases takes,
+// given the methods of the interfaces they're made of.
+func estimateLines(perms []int, ifaces []map[string]struct{}) int {
+	// The function itself, its switch, default case, and the type assertions
+	lines := 8 + len(ifaces)
+	for _, perm := range perms {
+		// 'case ...:', 'return struct {', '}{...}', and the fields
+		lines += 4
+		for i := range ifaces {
+			if perm>>i&0x1 == 1 {
+				lines++
+			}
+		}
+	}
+	// Each method is forwarded by a three line function
+	methods := map[string]struct{}{}
+	for _, ifc := range ifaces {
+		for name := range ifc {
+			methods[name] = struct{}{}
+		}
+	}
+	return lines + 3*len(methods)
+}
+
+// eachClosed calls visit with each permutation of n interfaces which closure
+// leaves as it is, from the highest to the lowest, until visit returns false.
+// closure returns a permutation along with the interfaces it implies, be it
+// through the rules or through their methods.
+//
+// Rather than go through every permutation, it decides the interfaces one at
+// a time, and only includes one if what it implies isn't already left out.
+// Whatever it has decided on can be completed by leaving out the rest, so
+// there are no dead ends: each permutation it gets to is one to visit.
+func eachClosed(n int, closure func(perm int) int, visit func(perm int) bool) {
+	var walk func(i, ones, zeros int) bool
+	walk = func(i, ones, zeros int) bool {
+		if i < 0 {
+			return visit(ones)
+		}
+		bit := 1 << i
+		if with := closure(ones | bit); with&zeros == 0 {
+			if !walk(i-1, with, zeros) {
+				return false
+			}
+		}
+		if ones&bit == 0 {
+			return walk(i-1, ones, zeros|bit)
+		}
+		return true
+	}
+	walk(n-1, closure(0), 0)
+}
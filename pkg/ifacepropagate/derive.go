@@ -0,0 +1,118 @@
+package ifacepropagate
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// deriveInterfaces returns the capabilities to propagate for the given types,
+// i.e. the methods they have beyond the wrapped interface, minus the excluded
+// ones.
+//
+// Each method goes into one interface per distinct set of types which have
+// it. That keeps the number of cases we generate down, while still letting
+// any of the types, wrapped, keep all of its methods.
+func deriveInterfaces(pkg *packages.Package, sel *structSel, typeSpecs []string, exclude []string) ([]*iface, error) {
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		excluded[strings.TrimSpace(name)] = false
+	}
+
+	type derived struct {
+		method *types.Func
+		from   string
+		// owners has a bit set for each type which has the method
+		owners uint64
+	}
+	methods := map[string]*derived{}
+	order := []string{}
+
+	if len(typeSpecs) > 64 {
+		return nil, fmt.Errorf("can't derive methods from more than 64 types, got %d", len(typeSpecs))
+	}
+	for i, spec := range typeSpecs {
+		spec = strings.TrimSpace(spec)
+		t, err := parseTypeExpr(pkg, sel, spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q: %w", spec, err)
+		}
+		mset := types.NewMethodSet(t)
+		for j := 0; j < mset.Len(); j++ {
+			method := mset.At(j).Obj().(*types.Func)
+			name := method.Name()
+			if _, ok := excluded[name]; ok {
+				excluded[name] = true
+				continue
+			}
+			if sel.iface.hasMethod(name) {
+				continue
+			}
+			// There's no way to name another package's unexported methods
+			if !method.Exported() && method.Pkg() != nil && method.Pkg().Path() != pkg.PkgPath {
+				continue
+			}
+
+			sig := methodSignature(method)
+			if obj := unnameableType(pkg, sig); obj != nil {
+				return nil, fmt.Errorf(
+					"method %q of %v can't be propagated: it uses %v, which is unexported in package %q and so can't be referred to from %q; exclude it to propagate the rest",
+					name, spec, obj.Name(), obj.Pkg().Path(), pkg.PkgPath,
+				)
+			}
+			if prev, ok := methods[name]; ok {
+				if !identicalSignatures(prev.method.Type(), sig) {
+					return nil, fmt.Errorf(
+						"conflicting method %q: %v declares it as %v, but %v declares it as %v; exclude it or remove one of the types",
+						name,
+						prev.from, types.TypeString(prev.method.Type(), nil),
+						spec, types.TypeString(sig, nil),
+					)
+				}
+				prev.owners |= 1 << i
+				continue
+			}
+			methods[name] = &derived{
+				method: types.NewFunc(token.NoPos, method.Pkg(), name, sig),
+				from:   spec,
+				owners: 1 << i,
+			}
+			order = append(order, name)
+		}
+	}
+
+	for _, name := range exclude {
+		if !excluded[strings.TrimSpace(name)] {
+			return nil, fmt.Errorf("excluded method %q is not a method of any of %v", name, strings.Join(typeSpecs, ", "))
+		}
+	}
+
+	groups := map[uint64][]*types.Func{}
+	groupOrder := []uint64{}
+	for _, name := range order {
+		d := methods[name]
+		if _, ok := groups[d.owners]; !ok {
+			groupOrder = append(groupOrder, d.owners)
+		}
+		groups[d.owners] = append(groups[d.owners], d.method)
+	}
+	if len(groupOrder) == 0 {
+		return nil, fmt.Errorf("%v have no methods beyond those of %v to propagate", strings.Join(typeSpecs, ", "), sel.iface)
+	}
+
+	ret := make([]*iface, 0, len(groupOrder))
+	for _, owners := range groupOrder {
+		obj := types.NewInterfaceType(groups[owners], nil).Complete()
+		ret = append(ret, &iface{
+			pkgName:          pkg.Name,
+			pkgPath:          pkg.PkgPath,
+			isCurrentPackage: true,
+			obj:              obj,
+			literal:          types.TypeString(obj, func(p *types.Package) string { return p.Path() }),
+		})
+	}
+	return ret, nil
+}
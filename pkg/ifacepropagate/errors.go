@@ -0,0 +1,156 @@
+package ifacepropagate
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// ArgumentError is returned if an argument is malformed, such as a struct
+// selector without a dot or an interface literal which doesn't parse, or if
+// the options contradict each other.
+type ArgumentError struct {
+	// Arg is the malformed argument, or empty if it's the options which are
+	// at fault.
+	Arg string
+	Err error
+}
+
+func (e *ArgumentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// StructNotFoundError is returned if the struct selector names a struct the
+// package doesn't declare.
+type StructNotFoundError struct {
+	// Package is the import path of the package the struct was looked up in.
+	Package string
+	Struct  string
+	// Found is what the package declares by that name instead, if anything,
+	// and Pos is where.
+	Found types.Object
+	Pos   token.Position
+}
+
+func (e *StructNotFoundError) Error() string {
+	if e.Found == nil {
+		return fmt.Sprintf("could not find any struct named %q in package %q", e.Struct, e.Package)
+	}
+	return fmt.Sprintf("%s%q in package %q is not a struct", posPrefix(e.Pos), e.Struct, e.Package)
+}
+
+// FieldNotFoundError is returned if the struct selector refers to a field
+// which doesn't exist.
+type FieldNotFoundError struct {
+	Package string
+	Struct  string
+	// Field is the path of the field, such as 'statusWriter.base.Writer'.
+	Field string
+	// Found is the method by that name, if it's one, rather than a field.
+	Found types.Object
+	// Pos is where the struct or field which lacks the field is declared.
+	Pos token.Position
+}
+
+func (e *FieldNotFoundError) Error() string {
+	if e.Found != nil {
+		return fmt.Sprintf("%s'%v' in package %q is a method, not a field", posPrefix(e.Pos), e.Field, e.Package)
+	}
+	lastDot := strings.LastIndex(e.Field, ".")
+	return fmt.Sprintf("%s'%v' in package %q has no field %q", posPrefix(e.Pos), e.Field[:lastDot], e.Package, e.Field[lastDot+1:])
+}
+
+// NotInterfaceError is returned if the field the struct selector refers to
+// isn't of an interface type.
+type NotInterfaceError struct {
+	Package string
+	Struct  string
+	// Field is the path of the field, such as 'statusWriter.base.Writer'.
+	Field string
+	Type  types.Type
+	// Pos is where the field is declared.
+	Pos token.Position
+}
+
+func (e *NotInterfaceError) Error() string {
+	return fmt.Sprintf("%s'%v' in package %q is of type %v, which is not an interface", posPrefix(e.Pos), e.Field, e.Package, e.Type)
+}
+
+// InterfaceNotFoundError is returned if one of the interfaces to propagate
+// doesn't exist.
+type InterfaceNotFoundError struct {
+	// Package is the import path of the package the interface was looked up
+	// in.
+	Package   string
+	Interface string
+	// Found is what the package declares by that name instead, if anything.
+	Found types.Object
+}
+
+func (e *InterfaceNotFoundError) Error() string {
+	if e.Found == nil {
+		return fmt.Sprintf("no interface named %q in package %q", e.Interface, e.Package)
+	}
+	return fmt.Sprintf("%q in package %q is not an interface", e.Interface, e.Package)
+}
+
+// PackageLoadError is returned if a package the interfaces or types refer to
+// can't be loaded.
+type PackageLoadError struct {
+	Package string
+	Err     error
+}
+
+func (e *PackageLoadError) Error() string {
+	return fmt.Sprintf("error loading package %q: %v", e.Package, e.Err)
+}
+
+func (e *PackageLoadError) Unwrap() error {
+	return e.Err
+}
+
+// TooManyCasesError is returned if the code would have more cases than the
+// maximum given with MaxCases. We stop counting them once there are more, so
+// there may be many more than that.
+type TooManyCasesError struct {
+	// Interfaces is the number of interfaces left after pruning.
+	Interfaces int
+	Max        int
+	// EstimatedLines is about how many lines of code the first Max+1 cases
+	// would take.
+	EstimatedLines int
+	// Realized is whether the cases were already limited to the types given
+	// with RealizedBy.
+	Realized bool
+}
+
+func (e *TooManyCasesError) Error() string {
+	cases := "cases"
+	if e.Max == 1 {
+		cases = "case"
+	}
+	realize := "name the types the interface holds (RealizedBy, -realized-by)"
+	if e.Realized {
+		realize = "name fewer of the types the interface holds (RealizedBy, -realized-by)"
+	}
+	return fmt.Sprintf(
+		"propagating %d interfaces would take more than the maximum of %d %s, which alone come to about %d lines of code; "+
+			"to cut them down, propagate fewer interfaces, declare which of them imply others (Implies, -implies), %s, "+
+			"or raise the maximum (MaxCases, -max-cases)",
+		e.Interfaces, e.Max, cases, e.EstimatedLines, realize,
+	)
+}
+
+// posPrefix returns the 'file:line:col: ' prefix for an error at pos, or
+// nothing if pos is unknown.
+func posPrefix(pos token.Position) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	return pos.String() + ": "
+}
@@ -8,14 +8,21 @@ import (
 	"go/parser"
 	"go/token"
 	"go/types"
+	"math/bits"
+	"sort"
 	"strings"
 
-	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
 )
 
 const generatedPrefix = "// Code generated by github.com/euank/ifacepropagate"
 
+// IsGenerated reports whether the given source was generated by
+// PropogateInterfaces.
+func IsGenerated(src []byte) bool {
+	return bytes.HasPrefix(src, []byte(generatedPrefix))
+}
+
 // PropogateInterfaces wraps the given interface in the given package to allow
 // also implementing a named set of additional interfaces iff the given
 // wrappingInterface also implements them, as determined at runtime.
@@ -37,29 +44,105 @@ const generatedPrefix = "// Code generated by github.com/euank/ifacepropagate"
 //        // returns a type that implements 'io.Closer' iff f.Reader implements 'io.Closer'
 //     }
 //
+// Besides named interfaces, wrappedInterfaces may contain instantiated generic
+// interfaces, such as "example.com/cache.Invalidator[string]", and interface
+// literals, such as "interface{ CloseWrite() error }". See the options for
+// other ways to pick what to propagate.
+//
+// Errors about what the arguments refer to are one of *StructNotFoundError,
+// *FieldNotFoundError, *NotInterfaceError, *InterfaceNotFoundError or
+// *PackageLoadError, which can be told apart with errors.As, as can
+// *ArgumentError for malformed arguments and *TooManyCasesError.
+//
 // Why is this ever useful? See https://medium.com/@cep21/interface-wrapping-method-erasure-c523b3549912
 func PropogateInterfaces(
 	pkg *packages.Package,
 	wrapperFuncName string,
 	structSelector string,
 	wrappedInterfaces []string,
+	opts ...Option,
 ) (string, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	structSel, err := parseStructSel(pkg, structSelector)
 	if err != nil {
 		return "", err
 	}
 
+	structSel.generated = generatedFile(pkg, structSel, wrapperFuncName)
 	userImpldFuncs := structMethodLookup(structSel)
 
+	if o.methods && o.types {
+		return "", &ArgumentError{Err: fmt.Errorf("methods and types can't be propagated at the same time")}
+	}
+	if len(o.exclude) > 0 && !o.types {
+		return "", &ArgumentError{Err: fmt.Errorf("methods can only be excluded when propagating types")}
+	}
+	if len(o.implies) > 0 && o.types {
+		return "", &ArgumentError{Err: fmt.Errorf("implications can't be declared when propagating types")}
+	}
+	if o.fallback != FallbackBase && o.fallback != FallbackNearest {
+		return "", &ArgumentError{Err: fmt.Errorf("unknown fallback %d", o.fallback)}
+	}
+
 	// And now look up all the interfaces we're supposed to wrap
 	wrappingIfaces := make([]*iface, 0, len(wrappedInterfaces))
-	for _, wiface := range wrappedInterfaces {
-		wi, err := parseInterface(pkg, wiface)
+	if o.types {
+		wrappingIfaces, err = deriveInterfaces(pkg, structSel, wrappedInterfaces, o.exclude)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		for _, wiface := range wrappedInterfaces {
+			parse := parseInterface
+			if o.methods {
+				parse = parseMethod
+			}
+			wi, err := parse(pkg, structSel, wiface)
+			if err != nil {
+				return "", err
+			}
+			if err := checkNameable(pkg, wi); err != nil {
+				return "", err
+			}
+			wrappingIfaces = append(wrappingIfaces, wi)
+		}
+	}
+
+	// No type can implement two interfaces which disagree on a method, so
+	// there's no sensible code to generate for those.
+	if err := checkMethodConflicts(append([]*iface{structSel.iface}, wrappingIfaces...)); err != nil {
+		return "", err
+	}
+
+	// Interfaces which add no methods would only double the number of cases
+	givenIfaces := wrappingIfaces
+	wrappingIfaces, pruned := pruneInterfaces(structSel.iface, wrappingIfaces)
+	if len(wrappingIfaces) > maxInterfaces {
+		return "", fmt.Errorf("can't propagate more than %d interfaces at once, got %d", maxInterfaces, len(wrappingIfaces))
+	}
+	report := &Report{Pruned: pruned}
+	if o.report != nil {
+		report = o.report
+		*report = Report{Pruned: pruned}
+	}
+	rules, err := resolveImplications(o.implies, structSel.iface, wrappedInterfaces, givenIfaces, wrappingIfaces, pruned)
+	if err != nil {
+		return "", err
+	}
+	var realized map[int]bool
+	if len(o.realized) > 0 {
+		realized, err = realizedPerms(pkg, structSel, o.realized, wrappingIfaces)
 		if err != nil {
 			return "", err
 		}
-		wrappingIfaces = append(wrappingIfaces, wi)
 	}
+	// Whether values may get to the default case, rather than just ones of
+	// combinations we know can't exist
+	fallback := len(rules) > 0 || realized != nil || o.fallback == FallbackNearest
 
 	// And now begin constructing the file
 	f, err := parser.ParseFile(pkg.Fset, "_ifacepropagate_generated.go", "package "+pkg.Name, parser.PackageClauseOnly)
@@ -67,22 +150,35 @@ func PropogateInterfaces(
 		return "", err
 	}
 
-	allIfaces := append([]*iface{structSel.iface}, wrappingIfaces...)
-	// Add the imports for all interfaces we're going to juggle
-	for _, iface := range allIfaces {
-		if iface.isCurrentPackage {
-			continue
-		}
-		astutil.AddImport(pkg.Fset, f, iface.pkgPath)
-	}
+	// Imports are added as we go; the receiver and the struct's type
+	// parameters are in scope everywhere we refer to a package, so no import
+	// may be named like them.
+	renderer := newTypeRenderer(pkg.Types, append([]string{structSel.receiver}, structSel.typeParamNames...)...)
+	renderer.renameTypeParams(structSel.named.TypeParams(), structSel.typeParamNames)
 
 	decls := []ast.Decl{}
 
 	// We need to alias any interfaces that have overlapping names, or else we
 	// won't be able to construct structs as we do below.
-	wrappingIfaces, aliases := aliasInterfaces(pkg, structSel.iface, wrappingIfaces)
+	// Remember which interface each declaration is for, so that verify can
+	// tell what's wrong.
+	origins := map[ast.Decl]string{}
+	keptIfaces := wrappingIfaces
+	wrappingIfaces, aliases := aliasInterfaces(pkg, renderer, structSel, wrappingIfaces)
+	for _, decl := range aliases {
+		name := decl.(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Name.Name
+		for i, ifc := range wrappingIfaces {
+			if ifc.name == name {
+				origins[decl] = keptIfaces[i].String()
+			}
+		}
+	}
 	decls = append(decls, aliases...)
 
+	// Interfaces may also share methods with the base interface or with each
+	// other, which would make those methods ambiguous in the structs below.
+	composer := newComposer(pkg, renderer, structSel, append([]*iface{structSel.iface}, wrappingIfaces...))
+
 	// generate the function body
 	body := &ast.BlockStmt{
 		List: []ast.Stmt{},
@@ -97,36 +193,133 @@ func PropogateInterfaces(
 			Tok: token.DEFINE,
 			Rhs: []ast.Expr{
 				&ast.TypeAssertExpr{
-					X: &ast.SelectorExpr{
-						X:   ast.NewIdent(structSel.receiver),
-						Sel: ast.NewIdent(structSel.member.Name()),
-					},
-					Type: iface.expr(),
+					X:    structSel.fieldExpr(),
+					Type: iface.expr(renderer),
 				},
 			},
 		})
 	}
 	// we now have ok1..n for which interfaces it implements. Now generate the switch statement
-	numPerms := 2 << (len(wrappingIfaces) - 1)
+	// Pick the cases before generating any, so that we can tell if there'd
+	// be too many of them.
+	numPerms := 1 << len(wrappingIfaces)
+	baseMethods := methodNames(structSel.iface)
+	ifaceMethods := make([]map[string]struct{}, 0, len(wrappingIfaces))
+	for _, ifc := range wrappingIfaces {
+		ifaceMethods = append(ifaceMethods, methodNames(ifc))
+	}
+	perms := []int{}
+	closedPerms := 0
+	// pick gives perm a case, unless no value can get to it, and returns
+	// whether to go on, i.e. false once there are more cases than we may
+	// generate; there's no telling how many more there would be.
+	pick := func(perm int) bool {
+		if !consistent(rules, perm) {
+			report.ImpliedCases++
+			return true
+		}
+		// Skip the cases no value can get to, i.e. 'ok0 && !ok1' if
+		// implementing the first interface means implementing the second too
+		if !feasible(baseMethods, ifaceMethods, perm) {
+			report.SkippedCases++
+			return true
+		}
+		if o.fallback == FallbackNearest && perm == 0 && len(wrappingIfaces) > 0 {
+			// That's what the default case is for
+			return true
+		}
+		perms = append(perms, perm)
+		return o.maxCases <= 0 || len(perms) <= o.maxCases
+	}
+	if realized != nil {
+		// Only the permutations the types implement can get a case, and they're
+		// few, unlike all of them, which may be too many to go through.
+		realizedPerms := make([]int, 0, len(realized))
+		for perm := range realized {
+			realizedPerms = append(realizedPerms, perm)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(realizedPerms)))
+		report.UnrealizedCases = numPerms - len(realizedPerms)
+		for _, perm := range realizedPerms {
+			if !pick(perm) {
+				break
+			}
+		}
+	} else {
+		// Only the permutations the rules allow and values can get to are gone
+		// through, since they may be few out of many, so that going through
+		// them takes no longer than generating their cases.
+		closure := func(perm int) int {
+			for {
+				next := covering(baseMethods, ifaceMethods, implied(rules, perm))
+				if next == perm {
+					return perm
+				}
+				perm = next
+			}
+		}
+		eachClosed(len(wrappingIfaces), closure, func(perm int) bool {
+			closedPerms++
+			return pick(perm)
+		})
+	}
+	if o.maxCases > 0 && len(perms) > o.maxCases {
+		return "", &TooManyCasesError{
+			Interfaces:     len(wrappingIfaces),
+			Max:            o.maxCases,
+			EstimatedLines: estimateLines(perms, ifaceMethods),
+			Realized:       realized != nil,
+		}
+	}
+	if realized == nil {
+		consistentPerms := countConsistent(len(wrappingIfaces), rules)
+		report.ImpliedCases = numPerms - consistentPerms
+		report.SkippedCases = consistentPerms - closedPerms
+	}
+	report.Cases = len(perms)
+	if o.fallback == FallbackNearest {
+		// The cases only check for the interfaces they need, so the first
+		// one that matches has to be the one with the most of them.
+		sort.SliceStable(perms, func(i, j int) bool {
+			return bits.OnesCount(uint(perms[i])) > bits.OnesCount(uint(perms[j]))
+		})
+	}
+
 	cases := []ast.Stmt{}
-	for perm := numPerms - 1; perm >= 0; perm-- {
+	okUsed := make([]bool, len(wrappingIfaces))
+	for _, perm := range perms {
 		// more than 1 iface means we need to wrap them all in a binary expression
 		binaryParts := []ast.Expr{}
-		// Always include the base interface
-		bodyIfaces := []*iface{structSel.iface}
+		bodyIfaces := []*iface{}
 		for i, iface := range wrappingIfaces {
 			okNum := fmt.Sprintf("ok%d", i)
 			if perm>>i&0x1 == 1 {
 				// This one is enabled, so this iface should be used
 				bodyIfaces = append(bodyIfaces, iface)
 				binaryParts = append(binaryParts, ast.NewIdent(okNum))
-			} else {
+				okUsed[i] = true
+			} else if o.fallback != FallbackNearest {
 				binaryParts = append(binaryParts, &ast.UnaryExpr{
 					Op: token.NOT,
 					X:  ast.NewIdent(okNum),
 				})
+				okUsed[i] = true
 			}
 		}
+		// Now the body
+		selectBody := []ast.Stmt{
+			&ast.ReturnStmt{
+				Results: []ast.Expr{
+					genInterfaceStruct(renderer, structSel, composer.compose(structSel.iface, bodyIfaces)),
+				},
+			},
+		}
+		if len(wrappingIfaces) == 0 {
+			// Everything was pruned, so there's nothing to switch on
+			body.List = append(body.List, selectBody...)
+			break
+		}
+
 		// We have the select condition and the ifaces to use in this case.
 		// And em all together
 		var caseClause ast.Expr
@@ -141,47 +334,62 @@ func PropogateInterfaces(
 		}
 		// and now we have 'ok0 && !ok1 && ok2 ....' for this perm
 
-		// Now the body
-		selectBody := []ast.Stmt{
-			&ast.ReturnStmt{
-				Results: []ast.Expr{
-					genInterfaceStruct(structSel, bodyIfaces),
-				},
-			},
-		}
-
 		cases = append(cases, &ast.CaseClause{
 			List: []ast.Expr{caseClause},
 			Body: selectBody,
 		})
 	}
 	// Final case, include the default panic
-	cases = append(cases, &ast.CaseClause{
-		Body: []ast.Stmt{
-			&ast.ExprStmt{
-				X: &ast.CallExpr{
-					Fun: &ast.Ident{
-						Name: "panic",
-					},
-					Args: []ast.Expr{
-						&ast.BasicLit{
-							Kind:  token.STRING,
-							Value: `"unreachable"`,
-						},
+	if len(wrappingIfaces) > 0 {
+		var defaultStmt ast.Stmt = &ast.ExprStmt{
+			X: &ast.CallExpr{
+				Fun: &ast.Ident{
+					Name: "panic",
+				},
+				Args: []ast.Expr{
+					&ast.BasicLit{
+						Kind:  token.STRING,
+						Value: `"unreachable"`,
 					},
 				},
 			},
-		},
-	})
+		}
+		if fallback {
+			// Values breaking the implications, or of types we weren't told
+			// about, do get here; the base interface is all we can safely say
+			// they implement.
+			defaultStmt = &ast.ReturnStmt{
+				Results: []ast.Expr{
+					genInterfaceStruct(renderer, structSel, []*iface{structSel.iface}),
+				},
+			}
+		}
+		cases = append(cases, &ast.CaseClause{
+			Body: []ast.Stmt{defaultStmt},
+		})
 
-	body.List = append(body.List, &ast.SwitchStmt{
-		Body: &ast.BlockStmt{
-			List: cases,
-		},
-	})
+		// Cases which only check for the interfaces they need may not check
+		// for all of them, and the compiler won't have unused oks.
+		assigns := body.List
+		body.List = []ast.Stmt{}
+		for i, assign := range assigns {
+			if okUsed[i] {
+				body.List = append(body.List, assign)
+			}
+		}
+
+		body.List = append(body.List, &ast.SwitchStmt{
+			Body: &ast.BlockStmt{
+				List: cases,
+			},
+		})
+	}
+
+	decls = append(decls, composer.decls...)
 
 	// And now for the function
 	wrapFunc := structSel.declareFunction(
+		renderer,
 		wrapperFuncName,
 		body,
 	)
@@ -190,11 +398,11 @@ func PropogateInterfaces(
 
 	// And now generate all the interface implementations that we need
 	impldFuncs := map[string]struct{}{}
-	for _, iface := range wrappingIfaces {
+	for j, iface := range wrappingIfaces {
 		for i := 0; i < iface.obj.NumMethods(); i++ {
 			method := iface.obj.Method(i)
 			if _, ok := impldFuncs[method.Name()]; ok {
-				// already impld; hope they're compatible, otherwise we'll fail to compile
+				// already impld; checkMethodConflicts made sure it's compatible
 				continue
 			}
 			// Also skip all functions the author of the struct has implemented
@@ -202,12 +410,20 @@ func PropogateInterfaces(
 			if _, ok := userImpldFuncs[method.Name()]; ok {
 				continue
 			}
-			implFunc := structSel.implementMethod(iface, method)
+			// And the base interface's methods, which the struct has to have
+			// already, be it by embedding the interface or implementing them.
+			if structSel.iface.hasMethod(method.Name()) {
+				continue
+			}
+			implFunc := structSel.implementMethod(renderer, iface, method)
 			impldFuncs[method.Name()] = struct{}{}
+			origins[implFunc] = fmt.Sprintf("method %s of %v", method.Name(), keptIfaces[j])
 			decls = append(decls, implFunc)
 		}
 	}
 
+	renderer.addImports(pkg.Fset, f)
+
 	var buf bytes.Buffer
 	buf.WriteString(generatedPrefix + "\n\n")
 	if err := format.Node(&buf, pkg.Fset, f); err != nil {
@@ -217,7 +433,14 @@ func PropogateInterfaces(
 	if err := format.Node(&buf, pkg.Fset, decls); err != nil {
 		return "", err
 	}
-	return buf.String(), err
+
+	if !o.noVerify {
+		if err := verify(pkg, structSel, wrapperFuncName, buf.String(), decls, origins); err != nil {
+			return "", err
+		}
+	}
+	report.Lines = strings.Count(buf.String(), "\n")
+	return buf.String(), nil
 }
 
 type structSel struct {
@@ -226,14 +449,24 @@ type structSel struct {
 	structName      string
 	structObj       *types.Struct
 	named           *types.Named
-	member          *types.TypeName
 	iface           *iface
+	// typeParamNames are the names the selector gave the struct's type
+	// parameters, if it's generic.
+	typeParamNames []string
+	// fieldPath holds the fields leading from the struct to the interface we
+	// wrap, i.e. [base, ResponseWriter] for 'statusWriter.base.ResponseWriter'.
+	// Each of them may or may not be embedded.
+	fieldPath []*types.Var
+	// generated is the file we generated before for the struct, with the
+	// same wrapper function, if the package has one. What it declares is
+	// about to be replaced, so it doesn't count as the package's.
+	generated *ast.File
 }
 
 func parseStructSel(pkg *packages.Package, s string) (*structSel, error) {
 	parts := strings.SplitN(s, " ", 2)
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("struct selector must contain a space after the receiver name")
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("struct selector must contain a space after the receiver name")}
 	}
 
 	recv := parts[0]
@@ -242,61 +475,262 @@ func parseStructSel(pkg *packages.Package, s string) (*structSel, error) {
 		// chop off the '*'
 		parts[1] = parts[1][1:]
 	}
+	typeParamNames := []string{}
+	if open := strings.Index(parts[1], "["); open != -1 {
+		close := strings.Index(parts[1], "]")
+		if close < open {
+			return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("the type parameter list in %v is missing its closing ']'", parts[1])}
+		}
+		for _, name := range strings.Split(parts[1][open+1:close], ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("the type parameter list in %v has an empty name", parts[1])}
+			}
+			typeParamNames = append(typeParamNames, name)
+		}
+		// chop off the type parameters, they don't matter for finding the field
+		parts[1] = parts[1][:open] + parts[1][close+1:]
+	}
+
 	selParts := strings.Split(parts[1], ".")
-	if len(selParts) != 2 {
-		return nil, fmt.Errorf("the struct selector must be of the form 'structName.Field', but %v did not have a dot", parts[1])
+	if len(selParts) < 2 {
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("the struct selector must be of the form 'structName.Field' or 'structName.field.Field', but %v did not have a dot", parts[1])}
 	}
 
-	structName, memberName := selParts[0], selParts[1]
+	structName, memberNames := selParts[0], selParts[1:]
 
 	obj := pkg.Types.Scope().Lookup(structName)
 	if obj == nil {
-		return nil, fmt.Errorf("Could not find any struct named %q in package %q", structName, pkg.Name)
+		return nil, &StructNotFoundError{Package: pkg.PkgPath, Struct: structName}
+	}
+	if _, ok := obj.(*types.TypeName); !ok {
+		return nil, &StructNotFoundError{Package: pkg.PkgPath, Struct: structName, Found: obj, Pos: pkg.Fset.Position(obj.Pos())}
+	}
+	// The struct may be an alias, in which case we keep using the alias's
+	// name, but it has to denote a type we can declare methods on.
+	named, ok := types.Unalias(obj.Type()).(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg.Types || named.Origin() != named {
+		return nil, errorAt(pkg, obj.Pos(), "%q in package %q is an alias of %v, which isn't a type declared in that package", structName, pkg.PkgPath, types.Unalias(obj.Type()))
+	}
+	if tparams := named.TypeParams(); tparams.Len() != len(typeParamNames) {
+		if len(typeParamNames) == 0 {
+			return nil, &ArgumentError{Arg: s, Err: errorAt(pkg, obj.Pos(), "%q in package %q is generic, so the selector must name its %d type parameters, i.e. '%v[T1, T2].Field'", structName, pkg.PkgPath, tparams.Len(), structName)}
+		}
+		return nil, &ArgumentError{Arg: s, Err: errorAt(pkg, obj.Pos(), "%q in package %q has %d type parameters, but the selector names %d", structName, pkg.PkgPath, tparams.Len(), len(typeParamNames))}
+	}
+	structObj, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, &StructNotFoundError{Package: pkg.PkgPath, Struct: structName, Found: obj, Pos: pkg.Fset.Position(obj.Pos())}
+	}
+
+	// Follow the path one field at a time; every field but the last one has
+	// to lead to another struct.
+	fieldPath := []*types.Var{}
+	cur := obj.Type()
+	// curPos is where cur comes from, to point errors at
+	curPos := obj.Pos()
+	for i, memberName := range memberNames {
+		parent := strings.Join(append([]string{structName}, memberNames[:i]...), ".")
+		path := parent + "." + memberName
+		memberObj, _, _ := types.LookupFieldOrMethod(cur, true, obj.Pkg(), memberName)
+		if memberObj == nil {
+			return nil, &FieldNotFoundError{Package: pkg.PkgPath, Struct: structName, Field: path, Pos: pkg.Fset.Position(curPos)}
+		}
+		field, ok := memberObj.(*types.Var)
+		if !ok {
+			return nil, &FieldNotFoundError{Package: pkg.PkgPath, Struct: structName, Field: path, Found: memberObj, Pos: pkg.Fset.Position(memberObj.Pos())}
+		}
+		fieldPath = append(fieldPath, field)
+		cur = field.Type()
+		curPos = field.Pos()
+
+		if i == len(memberNames)-1 {
+			break
+		}
+		under := cur.Underlying()
+		if ptr, ok := under.(*types.Pointer); ok {
+			under = ptr.Elem().Underlying()
+		}
+		if _, ok := under.(*types.Struct); !ok {
+			return nil, &FieldNotFoundError{Package: pkg.PkgPath, Struct: structName, Field: path + "." + memberNames[i+1], Pos: pkg.Fset.Position(curPos)}
+		}
 	}
 
-	memberObj, _, _ := types.LookupFieldOrMethod(obj.Type(), false, obj.Pkg(), memberName)
-	if memberObj == nil {
-		return nil, fmt.Errorf("struct %q in pkg %q had no member %q", structName, pkg.Name, memberName)
+	if !types.IsInterface(cur) {
+		return nil, &NotInterfaceError{Package: pkg.PkgPath, Struct: structName, Field: parts[1], Type: cur, Pos: pkg.Fset.Position(curPos)}
 	}
 
-	if !types.IsInterface(memberObj.Type()) {
-		return nil, fmt.Errorf("'%v.%v' in pkg %q was not an interface", structName, memberName, pkg.Name)
+	// Keep referring to the interface by whatever name the field's type was
+	// declared with, which may be an alias.
+	var ifaceObj *types.TypeName
+	var ifaceTypeArgs *types.TypeList
+	switch t := cur.(type) {
+	case *types.Named:
+		ifaceObj = t.Obj()
+		ifaceTypeArgs = t.TypeArgs()
+	case *types.Alias:
+		ifaceObj = t.Obj()
+		ifaceTypeArgs = t.TypeArgs()
+	default:
+		return nil, errorAt(pkg, curPos, "'%v' in package %q has the unnamed type %v; only named interfaces can be wrapped", parts[1], pkg.PkgPath, cur)
 	}
 
-	memberPkgPath := memberObj.(*types.Var).Type().(*types.Named).Obj().Pkg().Path()
-	return &structSel{
+	sel := &structSel{
 		receiver:        recv,
 		pointerReceiver: ptr,
 		structName:      structName,
-		named:           obj.Type().(*types.Named),
-		structObj:       obj.Type().(*types.Named).Underlying().(*types.Struct),
-		member:          memberObj.(*types.Var).Type().(*types.Named).Obj(),
-		iface: &iface{
-			pkgName:          memberObj.(*types.Var).Type().(*types.Named).Obj().Pkg().Name(),
-			pkgPath:          memberPkgPath,
-			isCurrentPackage: memberPkgPath == pkg.PkgPath,
-			name:             memberObj.Name(),
-			obj:              memberObj.Type().Underlying().(*types.Interface),
-		},
-	}, nil
+		named:           named,
+		structObj:       structObj,
+		fieldPath:       fieldPath,
+		iface:           ifaceFromTypeName(pkg, ifaceObj, cur, ifaceTypeArgs),
+		typeParamNames:  typeParamNames,
+	}
+	// We return the struct itself as the interface it wraps, which it only
+	// implements by itself if the field is embedded all the way down.
+	if missing := sel.missingMethods(); len(missing) > 0 {
+		recvType := structName
+		if ptr {
+			recvType = "*" + structName
+		}
+		return nil, errorAt(pkg, obj.Pos(),
+			"%v doesn't implement %v, which it wraps in '%v': it lacks %v; embed the field, or declare the methods",
+			recvType, sel.iface, parts[1], strings.Join(missing, ", "),
+		)
+	}
+	return sel, nil
+}
+
+// missingMethods returns the methods of the wrapped interface which the
+// receiver doesn't have, or has with a different signature.
+func (s *structSel) missingMethods() []string {
+	// Instantiating a generic struct with its own type parameters gets us its
+	// methods in terms of those, rather than their receivers' type parameters.
+	var recv types.Type = s.named
+	if tparams := s.named.TypeParams(); tparams.Len() > 0 {
+		targs := make([]types.Type, 0, tparams.Len())
+		for i := 0; i < tparams.Len(); i++ {
+			targs = append(targs, tparams.At(i))
+		}
+		inst, err := types.Instantiate(nil, s.named, targs, false)
+		if err != nil {
+			panic(fmt.Sprintf("instantiating %v with its own type parameters: %v", s.named, err))
+		}
+		recv = inst
+	}
+	valueRecv := recv
+	if s.pointerReceiver {
+		recv = types.NewPointer(recv)
+	}
+
+	missing := []string{}
+	for _, want := range unimplemented(recv, s.iface) {
+		if method := lookupMethod(recv, want.Name()); method != nil {
+			missing = append(missing, fmt.Sprintf("%v %v, having %v", want.Name(), types.TypeString(want.Type(), nil), types.TypeString(methodSignature(method), nil)))
+			continue
+		}
+		if !s.pointerReceiver && lookupMethod(types.NewPointer(valueRecv), want.Name()) != nil {
+			missing = append(missing, fmt.Sprintf("%v, which only *%v has", want.Name(), s.structName))
+			continue
+		}
+		missing = append(missing, want.Name())
+	}
+	return missing
+}
+
+// errorAt returns an error prefixed with the given position, in the usual
+// 'file:line:col' form, if it's known.
+func errorAt(pkg *packages.Package, pos token.Pos, format string, args ...interface{}) error {
+	return fmt.Errorf(posPrefix(pkg.Fset.Position(pos))+format, args...)
+}
+
+// ifaceFromTypeName returns the interface declared by the given type name,
+// instantiated as t with the given type arguments if it's generic.
+func ifaceFromTypeName(pkg *packages.Package, obj *types.TypeName, t types.Type, targs *types.TypeList) *iface {
+	ret := &iface{
+		isCurrentPackage: true,
+		name:             obj.Name(),
+		obj:              t.Underlying().(*types.Interface),
+	}
+	for i := 0; i < targs.Len(); i++ {
+		ret.typeArgs = append(ret.typeArgs, targs.At(i))
+	}
+	// Predeclared types, i.e. 'error', have no package
+	if obj.Pkg() != nil {
+		ret.pkgName = obj.Pkg().Name()
+		ret.pkgPath = obj.Pkg().Path()
+		ret.isCurrentPackage = obj.Pkg().Path() == pkg.PkgPath
+	}
+	return ret
 }
 
-func (s *structSel) declareFunction(name string, body *ast.BlockStmt) *ast.FuncDecl {
-	var recv ast.Expr
+// recvType returns the receiver type for methods on the struct, i.e. '*foo'
+// or 'foo[K, V]'.
+func (s *structSel) recvType() ast.Expr {
+	var ret ast.Expr = ast.NewIdent(s.structName)
+	if len(s.typeParamNames) > 0 {
+		indices := []ast.Expr{}
+		for _, name := range s.typeParamNames {
+			indices = append(indices, ast.NewIdent(name))
+		}
+		ret = &ast.IndexListExpr{X: ret, Indices: indices}
+	}
 	if s.pointerReceiver {
-		recv = &ast.StarExpr{
-			X: ast.NewIdent(s.structName),
+		ret = &ast.StarExpr{X: ret}
+	}
+	return ret
+}
+
+// typeParamFields returns the struct's type parameter list, i.e.
+// '[K comparable, V any]', or nil if it isn't generic. Types we declare for
+// the struct share its type parameters, as they may refer to them.
+func (s *structSel) typeParamFields(r *typeRenderer) *ast.FieldList {
+	if len(s.typeParamNames) == 0 {
+		return nil
+	}
+	ret := &ast.FieldList{}
+	tparams := s.named.TypeParams()
+	for i := 0; i < tparams.Len(); i++ {
+		ret.List = append(ret.List, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(s.typeParamNames[i])},
+			Type:  r.expr(tparams.At(i).Constraint()),
+		})
+	}
+	return ret
+}
+
+// typeArgs returns the struct's type parameters, for instantiating types we
+// declared with typeParamFields.
+func (s *structSel) typeArgs() []types.Type {
+	ret := []types.Type{}
+	tparams := s.named.TypeParams()
+	for i := 0; i < tparams.Len(); i++ {
+		ret = append(ret, tparams.At(i))
+	}
+	return ret
+}
+
+// fieldExpr returns the expression for the wrapped interface, i.e. 's.Conn'
+// or 's.base.Conn'.
+func (s *structSel) fieldExpr() ast.Expr {
+	var ret ast.Expr = ast.NewIdent(s.receiver)
+	for _, field := range s.fieldPath {
+		ret = &ast.SelectorExpr{
+			X:   ret,
+			Sel: ast.NewIdent(field.Name()),
 		}
-	} else {
-		recv = ast.NewIdent(s.structName)
 	}
+	return ret
+}
+
+func (s *structSel) declareFunction(r *typeRenderer, name string, body *ast.BlockStmt) *ast.FuncDecl {
+	recv := s.recvType()
 
 	return &ast.FuncDecl{
 		Name: ast.NewIdent(name),
 		Type: &ast.FuncType{
 			Params: &ast.FieldList{},
 			Results: &ast.FieldList{
-				List: []*ast.Field{{Type: s.iface.expr()}},
+				List: []*ast.Field{{Type: s.iface.expr(r)}},
 			},
 		},
 		Recv: &ast.FieldList{
@@ -316,63 +750,71 @@ func (s *structSel) declareFunction(name string, body *ast.BlockStmt) *ast.FuncD
 	}
 }
 
-func (s *structSel) implementMethod(iface *iface, method *types.Func) *ast.FuncDecl {
-	var recv ast.Expr
-	if s.pointerReceiver {
-		recv = &ast.StarExpr{
-			X: ast.NewIdent(s.structName),
-		}
-	} else {
-		recv = ast.NewIdent(s.structName)
-	}
+func (s *structSel) implementMethod(r *typeRenderer, iface *iface, method *types.Func) *ast.FuncDecl {
+	recv := s.recvType()
 
-	// TODO: variadic functions
 	sig := method.Type().(*types.Signature)
 	params := sig.Params()
 	args := []*ast.Field{}
 	callArgs := []ast.Expr{}
+	// The params and results live in the same scope as the receiver, and
+	// shadow anything the body needs to refer to, such as the package of the
+	// interface we're asserting to.
+	ifaceExpr := iface.expr(r)
+	used := map[string]struct{}{s.receiver: {}}
+	for _, name := range s.typeParamNames {
+		used[name] = struct{}{}
+	}
+	for _, ident := range referencedIdents(ifaceExpr) {
+		used[ident] = struct{}{}
+	}
+	paramNames := localNames(params, "p", used)
 	for i := 0; i < params.Len(); i++ {
 		arg := params.At(i)
+		argType := r.expr(arg.Type())
+		if sig.Variadic() && i == params.Len()-1 {
+			// the last param of a variadic func is typed as a slice, but we
+			// need to declare it as '...T'
+			argType = &ast.Ellipsis{
+				Elt: r.expr(arg.Type().(*types.Slice).Elem()),
+			}
+		}
 		args = append(args, &ast.Field{
-			Names: []*ast.Ident{ast.NewIdent(arg.Name())},
-			Type:  ast.NewIdent(arg.Type().String()),
+			Names: []*ast.Ident{ast.NewIdent(paramNames[i])},
+			Type:  argType,
 		})
-		// TODO: '...' calling
-		callArgs = append(callArgs, ast.NewIdent(arg.Name()))
+		callArgs = append(callArgs, ast.NewIdent(paramNames[i]))
 	}
 
 	results := []*ast.Field{}
 	ret := sig.Results()
+	resultNames := localNames(ret, "", used)
 	for i := 0; i < ret.Len(); i++ {
 		arg := ret.At(i)
-
-		typeName := arg.Type().String()
-		// render the type of foo/bar/baz.Qux as baz.Qux
-		if namedType, ok := arg.Type().(*types.Named); ok{
-			if namedType.Obj().Pkg() != nil {
-				typeName = fmt.Sprintf("%s.%s", namedType.Obj().Pkg().Name(), namedType.Obj().Name())
-			}
+		field := &ast.Field{Type: r.expr(arg.Type())}
+		// Results are either all named or all unnamed; we never refer to them,
+		// so just keep whatever the interface declared.
+		if resultNames[i] != "" {
+			field.Names = []*ast.Ident{ast.NewIdent(resultNames[i])}
 		}
-
-		results = append(results, &ast.Field{
-			Names: []*ast.Ident{ast.NewIdent(arg.Name())},
-			Type:  ast.NewIdent(typeName),
-		})
+		results = append(results, field)
 	}
 
 	callExpr := &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
 			X: &ast.TypeAssertExpr{
-				X: &ast.SelectorExpr{
-					X:   ast.NewIdent(s.receiver),
-					Sel: ast.NewIdent(s.member.Name()),
-				},
-				Type: iface.expr(),
+				X:    s.fieldExpr(),
+				Type: ifaceExpr,
 			},
 			Sel: ast.NewIdent(method.Name()),
 		},
 		Args: callArgs,
 	}
+	if sig.Variadic() {
+		// Any valid position works here, it just needs to tell the printer to
+		// spread the last argument with '...'
+		callExpr.Ellipsis = token.Pos(1)
+	}
 
 	body := []ast.Stmt{&ast.ExprStmt{X: callExpr}}
 	if len(results) > 0 {
@@ -407,69 +849,192 @@ func (s *structSel) implementMethod(iface *iface, method *types.Func) *ast.FuncD
 	}
 }
 
+// localNames returns a name for each variable in the given tuple which
+// doesn't collide with any of the 'used' identifiers, and marks those names
+// as used.
+// If synthPrefix is set, variables that are unnamed or named '_' get a
+// synthesized name based on their position, i.e. 'p0', so that they can be
+// referred to. Otherwise they're left as they were.
+func localNames(tuple *types.Tuple, synthPrefix string, used map[string]struct{}) []string {
+	ret := make([]string, tuple.Len())
+	// Keep every name we can first, so that renaming never steals one of them.
+	for i := 0; i < tuple.Len(); i++ {
+		name := tuple.At(i).Name()
+		if name == "" || name == "_" {
+			continue
+		}
+		if _, taken := used[name]; taken {
+			continue
+		}
+		ret[i] = name
+		used[name] = struct{}{}
+	}
+
+	for i := 0; i < tuple.Len(); i++ {
+		if ret[i] != "" {
+			continue
+		}
+		name := tuple.At(i).Name()
+		if name == "" || name == "_" {
+			if synthPrefix == "" {
+				ret[i] = name
+				continue
+			}
+			name = fmt.Sprintf("%s%d", synthPrefix, i)
+		}
+		for {
+			if _, taken := used[name]; !taken {
+				break
+			}
+			name += "_"
+		}
+		used[name] = struct{}{}
+		ret[i] = name
+	}
+	return ret
+}
+
+// referencedIdents returns every identifier that the given expression
+// resolves in the scope it's used in, i.e. 'io' and 'T' for 'io.Reader[T]'.
+func referencedIdents(e ast.Expr) []string {
+	ret := []string{}
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// 'Sel' is resolved relative to 'X', not the scope
+			ret = append(ret, referencedIdents(n.X)...)
+			return false
+		case *ast.Ident:
+			ret = append(ret, n.Name)
+		}
+		return true
+	})
+	return ret
+}
+
 type iface struct {
 	pkgPath          string
 	pkgName          string
 	isCurrentPackage bool
 	name             string
 	obj              *types.Interface
+	// typeArgs instantiate the interface, if it's generic
+	typeArgs []types.Type
+	// literal is set for interfaces given as a literal, such as
+	// 'interface{ CloseWrite() error }', which have no name of their own.
+	literal string
+	// method is set for single method interfaces we made up to propagate that
+	// method on its own. literal is set to the method it was given as.
+	method string
 }
 
-func parseInterface(pkg *packages.Package, s string) (*iface, error) {
-	// 'io.Reader' for example -> [io, Reader]
-	lastDot := strings.LastIndex(s, ".")
+func parseInterface(pkg *packages.Package, sel *structSel, s string) (*iface, error) {
+	if isInterfaceLiteral(s) {
+		return parseInterfaceLiteral(pkg, s)
+	}
+
+	// 'io.Reader' for example -> [io, Reader], and 'x/cache.Invalidator[K]'
+	// -> [x/cache, Invalidator] with the type arguments [K]
+	name, typeArgs, err := splitTypeArgs(s)
+	if err != nil {
+		return nil, err
+	}
+	lastDot := strings.LastIndex(name, ".")
 	var pkgName, ifaceName string
 	if lastDot == -1 {
-		ifaceName = s
+		ifaceName = name
 	} else {
-		pkgName, ifaceName = s[0:lastDot], s[lastDot+1:]
+		pkgName, ifaceName = name[0:lastDot], name[lastDot+1:]
 	}
 	// Same pkg case
 	ifacePkg := pkg
 	if pkgName != "" {
-		pkgs, err := packages.Load(&packages.Config{
-			Mode: packages.NeedTypes | packages.NeedName | packages.NeedSyntax | packages.NeedImports,
-		}, pkgName)
+		ifacePkg, err = loadPackage(pkg, pkgName)
 		if err != nil {
-			return nil, fmt.Errorf("error loading package %q: %w", pkgName, err)
+			return nil, err
 		}
-		ifacePkg = pkgs[0]
 	}
 
 	obj := ifacePkg.Types.Scope().Lookup(ifaceName)
+	if obj == nil && pkgName == "" {
+		// i.e. 'error'
+		obj = types.Universe.Lookup(ifaceName)
+	}
 	if obj == nil {
-		return nil, fmt.Errorf("no interface named %q in package %q", ifaceName, pkg.Name)
+		return nil, &InterfaceNotFoundError{Package: ifacePkg.PkgPath, Interface: ifaceName}
+	}
+
+	// A variable of an interface type would pass the IsInterface check too
+	tn, ok := obj.(*types.TypeName)
+	if !ok || !types.IsInterface(obj.Type()) {
+		return nil, &InterfaceNotFoundError{Package: ifacePkg.PkgPath, Interface: ifaceName, Found: obj}
+	}
+
+	t, err := instantiate(pkg, sel, tn, typeArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBasicInterface(s, t.Underlying().(*types.Interface)); err != nil {
+		return nil, err
+	}
+	ret := &iface{
+		pkgPath:          ifacePkg.PkgPath,
+		pkgName:          ifacePkg.Name,
+		isCurrentPackage: pkgName == "" || pkgName == pkg.PkgPath,
+		name:             ifaceName,
+		obj:              t.Underlying().(*types.Interface),
+	}
+	if named, ok := t.(*types.Named); ok {
+		for i := 0; i < named.TypeArgs().Len(); i++ {
+			ret.typeArgs = append(ret.typeArgs, named.TypeArgs().At(i))
+		}
 	}
+	return ret, nil
+}
 
-	if !types.IsInterface(obj.Type()) {
-		return nil, fmt.Errorf("%q in package %q is not an interface", ifaceName, pkgName)
+func (i *iface) String() string {
+	if i.literal != "" {
+		return i.literal
+	}
+	if i.pkgPath == "" {
+		return i.name
 	}
+	return i.pkgPath + "." + i.name
+}
 
-	return &iface{
-		ifacePkg.PkgPath,
-		ifacePkg.Name,
-		pkgName == "" || pkgName == pkg.PkgPath,
-		ifaceName,
-		obj.Type().Underlying().(*types.Interface),
-	}, nil
+func (i *iface) hasMethod(name string) bool {
+	for j := 0; j < i.obj.NumMethods(); j++ {
+		if i.obj.Method(j).Name() == name {
+			return true
+		}
+	}
+	return false
 }
 
-func (i *iface) expr() ast.Expr {
-	if i.isCurrentPackage {
-		return ast.NewIdent(i.name)
+func (i *iface) expr(r *typeRenderer) ast.Expr {
+	var ret ast.Expr = ast.NewIdent(i.name)
+	if !i.isCurrentPackage {
+		ret = &ast.SelectorExpr{
+			X:   ast.NewIdent(r.importName(i.pkgPath, i.pkgName)),
+			Sel: ast.NewIdent(i.name),
+		}
+	}
+	if len(i.typeArgs) == 0 {
+		return ret
 	}
-	return &ast.SelectorExpr{
-		X:   ast.NewIdent(i.pkgName),
-		Sel: ast.NewIdent(i.name),
+	indices := []ast.Expr{}
+	for _, targ := range i.typeArgs {
+		indices = append(indices, r.expr(targ))
 	}
+	return &ast.IndexListExpr{X: ret, Indices: indices}
 }
 
-func genInterfaceStruct(s *structSel, ifaces []*iface) *ast.CompositeLit {
+func genInterfaceStruct(r *typeRenderer, s *structSel, ifaces []*iface) *ast.CompositeLit {
 	fields := []*ast.Field{}
 	elts := []ast.Expr{}
 	for _, iface := range ifaces {
 		fields = append(fields, &ast.Field{
-			Type: iface.expr(),
+			Type: iface.expr(r),
 		})
 
 		elts = append(elts, ast.NewIdent(s.receiver))
@@ -484,45 +1049,49 @@ func genInterfaceStruct(s *structSel, ifaces []*iface) *ast.CompositeLit {
 	}
 }
 
-func aliasInterfaces(pkg *packages.Package, s *iface, ifaces []*iface) ([]*iface, []ast.Decl) {
+func aliasInterfaces(pkg *packages.Package, r *typeRenderer, sel *structSel, ifaces []*iface) ([]*iface, []ast.Decl) {
 	ret := make([]*iface, 0, len(ifaces))
-	used := map[string]struct{}{s.name: {}}
+	used := map[string]struct{}{sel.iface.name: {}}
 
 	decls := []ast.Decl{}
 
 	for _, ifc := range ifaces {
-		if _, taken := used[ifc.name]; !taken {
+		var name string
+		var declType ast.Expr
+		if ifc.literal != "" {
+			// Interface literals can't be embedded, so they always need a name
+			name = uniqueTypeName(pkg, sel, used, "ifacepropagateIface")
+			if ifc.method != "" {
+				name = "ifacepropagateMethod" + ifc.method
+				if _, taken := used[name]; taken || sel.declared(pkg, name) {
+					name = uniqueTypeName(pkg, sel, used, name)
+				}
+			}
+			declType = r.expr(ifc.obj)
+		} else if _, taken := used[ifc.name]; !taken {
 			ret = append(ret, ifc)
 			used[ifc.name] = struct{}{}
 			continue
-		}
-		// Otherwise, create an alias
-		name := "ifacepropagateIfaceAlias"
-		for suffix := 0; true; suffix++ {
-			candidate := name + fmt.Sprintf("%d", suffix)
-			if _, taken := used[candidate]; taken {
-				continue
-			}
-			if pkg.Types.Scope().Lookup(candidate) != nil {
-				continue
+		} else {
+			// Otherwise, create an alias
+			name = uniqueTypeName(pkg, sel, used, "ifacepropagateIfaceAlias")
+			declType = &ast.InterfaceType{
+				Methods: &ast.FieldList{
+					List: []*ast.Field{
+						{
+							Type: ifc.expr(r),
+						},
+					},
+				},
 			}
-			name = candidate
-			break
 		}
 		decls = append(decls, &ast.GenDecl{
 			Tok: token.TYPE,
 			Specs: []ast.Spec{
 				&ast.TypeSpec{
-					Name: ast.NewIdent(name),
-					Type: &ast.InterfaceType{
-						Methods: &ast.FieldList{
-							List: []*ast.Field{
-								{
-									Type: ifc.expr(),
-								},
-							},
-						},
-					},
+					Name:       ast.NewIdent(name),
+					TypeParams: sel.typeParamFields(r),
+					Type:       declType,
 				},
 			},
 		})
@@ -534,16 +1103,373 @@ func aliasInterfaces(pkg *packages.Package, s *iface, ifaces []*iface) ([]*iface
 			isCurrentPackage: true,
 			name:             name,
 			obj:              ifc.obj,
+			typeArgs:         sel.typeArgs(),
 		})
 	}
 
 	return ret, decls
 }
 
+// checkMethodConflicts returns an error if any two of the given interfaces
+// declare a method with the same name, but a different signature.
+func checkMethodConflicts(ifaces []*iface) error {
+	type declaration struct {
+		iface  *iface
+		method *types.Func
+	}
+	seen := map[string]declaration{}
+	for _, ifc := range ifaces {
+		for i := 0; i < ifc.obj.NumMethods(); i++ {
+			method := ifc.obj.Method(i)
+			prev, ok := seen[method.Name()]
+			if !ok {
+				seen[method.Name()] = declaration{ifc, method}
+				continue
+			}
+			if !identicalSignatures(prev.method.Type(), method.Type()) {
+				return fmt.Errorf(
+					"conflicting method %q: %v declares it as %v, but %v declares it as %v; remove one of them from the interfaces to propagate",
+					method.Name(),
+					prev.iface, types.TypeString(prev.method.Type(), nil),
+					ifc, types.TypeString(method.Type(), nil),
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// checkBasicInterface returns an error if t is a constraint, such as
+// 'comparable' or 'interface{ ~int | ~string }', rather than a basic
+// interface. Constraints may only be used as type parameter constraints, so we
+// could neither assert to nor embed them.
+func checkBasicInterface(name string, t *types.Interface) error {
+	if t.IsMethodSet() {
+		return nil
+	}
+	elem := "comparable"
+	for i := 0; i < t.NumEmbeddeds(); i++ {
+		embedded := t.EmbeddedType(i)
+		if ei, ok := embedded.Underlying().(*types.Interface); ok && ei.IsMethodSet() {
+			continue
+		}
+		elem = types.TypeString(embedded, nil)
+		break
+	}
+	if elem == name {
+		return fmt.Errorf("%q can't be propagated: constraints can't be used in type assertions or embedded in structs", name)
+	}
+	return fmt.Errorf(
+		"%q can't be propagated: it's a constraint because of its %q element, and constraints can't be used in type assertions or embedded in structs",
+		name, elem,
+	)
+}
+
+// checkNameable returns an error if the interface can't be implemented by
+// code in the target package, i.e. because it has unexported methods of
+// another package, or its methods use types we have no way to refer to.
+func checkNameable(pkg *packages.Package, ifc *iface) error {
+	for i := 0; i < ifc.obj.NumMethods(); i++ {
+		method := ifc.obj.Method(i)
+		if !method.Exported() && method.Pkg() != nil && method.Pkg().Path() != pkg.PkgPath {
+			return fmt.Errorf(
+				"%v can't be propagated: its method %q is unexported, so only types in package %q can implement it, not ones in %q",
+				ifc, method.Name(), method.Pkg().Path(), pkg.PkgPath,
+			)
+		}
+		if obj := unnameableType(pkg, method.Type()); obj != nil {
+			return fmt.Errorf(
+				"%v can't be propagated: its method %q uses %v, which is unexported in package %q and so can't be referred to from %q",
+				ifc, method.Name(), obj.Name(), obj.Pkg().Path(), pkg.PkgPath,
+			)
+		}
+	}
+	return nil
+}
+
+// unnameableType returns the first type name within t which is unexported in
+// a package other than the target package, or nil if there's none.
+func unnameableType(pkg *packages.Package, t types.Type) *types.TypeName {
+	checkObj := func(obj *types.TypeName) *types.TypeName {
+		if !obj.Exported() && obj.Pkg() != nil && obj.Pkg().Path() != pkg.PkgPath {
+			return obj
+		}
+		return nil
+	}
+	switch t := t.(type) {
+	case *types.Named:
+		if obj := checkObj(t.Obj()); obj != nil {
+			return obj
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if obj := unnameableType(pkg, t.TypeArgs().At(i)); obj != nil {
+				return obj
+			}
+		}
+	case *types.Alias:
+		if obj := checkObj(t.Obj()); obj != nil {
+			return obj
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if obj := unnameableType(pkg, t.TypeArgs().At(i)); obj != nil {
+				return obj
+			}
+		}
+	case *types.Pointer:
+		return unnameableType(pkg, t.Elem())
+	case *types.Slice:
+		return unnameableType(pkg, t.Elem())
+	case *types.Array:
+		return unnameableType(pkg, t.Elem())
+	case *types.Chan:
+		return unnameableType(pkg, t.Elem())
+	case *types.Map:
+		if obj := unnameableType(pkg, t.Key()); obj != nil {
+			return obj
+		}
+		return unnameableType(pkg, t.Elem())
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if obj := unnameableType(pkg, tuple.At(i).Type()); obj != nil {
+					return obj
+				}
+			}
+		}
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if obj := unnameableType(pkg, t.Field(i).Type()); obj != nil {
+				return obj
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if obj := unnameableType(pkg, t.EmbeddedType(i)); obj != nil {
+				return obj
+			}
+		}
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			if obj := unnameableType(pkg, t.ExplicitMethod(i).Type()); obj != nil {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// identicalSignatures reports whether the two method signatures are identical.
+// Interfaces from different packages are loaded separately, and so don't share
+// type objects for things like 'io.Reader'; fall back to comparing the fully
+// qualified types, sans parameter names, for those.
+func identicalSignatures(a, b types.Type) bool {
+	if types.Identical(a, b) {
+		return true
+	}
+	unnamed := func(t types.Type) string {
+		sig := t.(*types.Signature)
+		tuple := func(t *types.Tuple) *types.Tuple {
+			vars := make([]*types.Var, 0, t.Len())
+			for i := 0; i < t.Len(); i++ {
+				vars = append(vars, types.NewParam(token.NoPos, nil, "", t.At(i).Type()))
+			}
+			return types.NewTuple(vars...)
+		}
+		stripped := types.NewSignatureType(nil, nil, nil, tuple(sig.Params()), tuple(sig.Results()), sig.Variadic())
+		return types.TypeString(stripped, func(p *types.Package) string { return p.Path() })
+	}
+	return unnamed(a) == unnamed(b)
+}
+
+// uniqueTypeName returns the first of 'prefix0', 'prefix1', ... which is
+// neither used nor declared in the package, other than by the code we
+// generated before for the struct.
+func uniqueTypeName(pkg *packages.Package, sel *structSel, used map[string]struct{}, prefix string) string {
+	for suffix := 0; ; suffix++ {
+		candidate := fmt.Sprintf("%s%d", prefix, suffix)
+		if _, taken := used[candidate]; taken {
+			continue
+		}
+		if sel.declared(pkg, candidate) {
+			continue
+		}
+		return candidate
+	}
+}
+
+// composer decides what to embed in the struct we return for a given set of
+// interfaces, such that each method is promoted from exactly one embedded
+// field. Ambiguous selectors at the same depth would otherwise silently drop
+// out of the struct's method set.
+//
+// Interfaces are embedded as they are unless they share methods with the
+// base interface or an interface embedded before them. In that case we
+// declare and embed a 'partial' interface with just the methods that are
+// still missing, or nothing at all if there are none.
+type composer struct {
+	pkg      *packages.Package
+	r        *typeRenderer
+	sel      *structSel
+	used     map[string]struct{}
+	partials map[string]*iface
+	decls    []ast.Decl
+}
+
+func newComposer(pkg *packages.Package, r *typeRenderer, sel *structSel, ifaces []*iface) *composer {
+	used := map[string]struct{}{}
+	for _, ifc := range ifaces {
+		used[ifc.name] = struct{}{}
+	}
+	return &composer{
+		pkg:      pkg,
+		r:        r,
+		sel:      sel,
+		used:     used,
+		partials: map[string]*iface{},
+	}
+}
+
+func (c *composer) compose(base *iface, ifaces []*iface) []*iface {
+	ret := []*iface{base}
+	provided := map[string]struct{}{}
+	for i := 0; i < base.obj.NumMethods(); i++ {
+		provided[base.obj.Method(i).Name()] = struct{}{}
+	}
+
+	for _, ifc := range ifaces {
+		missing := []*types.Func{}
+		for i := 0; i < ifc.obj.NumMethods(); i++ {
+			method := ifc.obj.Method(i)
+			if _, ok := provided[method.Name()]; !ok {
+				missing = append(missing, method)
+			}
+		}
+		switch {
+		case len(missing) == ifc.obj.NumMethods():
+			ret = append(ret, ifc)
+		case len(missing) > 0:
+			ret = append(ret, c.partial(missing))
+		}
+		for _, method := range missing {
+			provided[method.Name()] = struct{}{}
+		}
+	}
+	return ret
+}
+
+// partial returns a local interface consisting of exactly the given methods,
+// declaring it if we haven't yet.
+func (c *composer) partial(methods []*types.Func) *iface {
+	names := make([]string, 0, len(methods))
+	for _, method := range methods {
+		names = append(names, method.Name())
+	}
+	key := strings.Join(names, ",")
+	if ret, ok := c.partials[key]; ok {
+		return ret
+	}
+
+	name := uniqueTypeName(c.pkg, c.sel, c.used, "ifacepropagatePartial")
+	c.used[name] = struct{}{}
+
+	fields := []*ast.Field{}
+	for _, method := range methods {
+		fields = append(fields, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(method.Name())},
+			Type:  c.r.funcType(method.Type().(*types.Signature)),
+		})
+	}
+	c.decls = append(c.decls, &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{
+			&ast.TypeSpec{
+				Name:       ast.NewIdent(name),
+				TypeParams: c.sel.typeParamFields(c.r),
+				Type: &ast.InterfaceType{
+					Methods: &ast.FieldList{List: fields},
+				},
+			},
+		},
+	})
+
+	ret := &iface{
+		pkgName:          c.pkg.Name,
+		pkgPath:          c.pkg.PkgPath,
+		isCurrentPackage: true,
+		name:             name,
+		obj:              types.NewInterfaceType(methods, nil).Complete(),
+		typeArgs:         c.sel.typeArgs(),
+	}
+	c.partials[key] = ret
+	return ret
+}
+
+// generatedFile returns the file of the package we generated before, with
+// the same wrapper function for the same struct, or nil if there's none.
+func generatedFile(pkg *packages.Package, sel *structSel, wrapperFuncName string) *ast.File {
+	for _, f := range pkg.Syntax {
+		if len(f.Comments) == 0 || f.Comments[0].List[0].Text != generatedPrefix {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != wrapperFuncName {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			switch x := recv.(type) {
+			case *ast.IndexExpr:
+				recv = x.X
+			case *ast.IndexListExpr:
+				recv = x.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok && ident.Name == sel.structName {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// replaced reports whether obj is declared by the code we generated before,
+// which the code we generate now replaces.
+func (s *structSel) replaced(obj types.Object) bool {
+	return s.generated != nil && s.generated.FileStart <= obj.Pos() && obj.Pos() < s.generated.FileEnd
+}
+
+// declared reports whether the package declares name, leaving out the code
+// we generated before for the struct.
+func (s *structSel) declared(pkg *packages.Package, name string) bool {
+	obj := pkg.Types.Scope().Lookup(name)
+	return obj != nil && !s.replaced(obj)
+}
+
+// structMethodLookup returns the names of all methods the struct already has,
+// be it declared on it directly or promoted from an embedded field.
 func structMethodLookup(sel *structSel) map[string]struct{} {
+	// Methods declared with either receiver count, since we can't declare the
+	// same method again with the other one.
 	ret := make(map[string]struct{}, sel.named.NumMethods())
 	for i := 0; i < sel.named.NumMethods(); i++ {
-		ret[sel.named.Method(i).Name()] = struct{}{}
+		if method := sel.named.Method(i); !sel.replaced(method) {
+			ret[method.Name()] = struct{}{}
+		}
+	}
+
+	// Promoted methods only count if they're in the method set of the
+	// receiver we generate methods for, i.e. a value receiver doesn't get the
+	// pointer methods of a struct embedded by value.
+	var recv types.Type = sel.named
+	if sel.pointerReceiver {
+		recv = types.NewPointer(sel.named)
+	}
+	mset := types.NewMethodSet(recv)
+	for i := 0; i < mset.Len(); i++ {
+		if method := mset.At(i).Obj(); !sel.replaced(method) {
+			ret[method.Name()] = struct{}{}
+		}
 	}
 	return ret
 }
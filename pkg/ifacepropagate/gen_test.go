@@ -0,0 +1,482 @@
+package ifacepropagate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// wideInterfaces returns n single method interface literals, for the methods
+// of the wide type in testdata/wrappers.
+func wideInterfaces(n int) []string {
+	ret := []string{}
+	for _, method := range wideMethods(n) {
+		ret = append(ret, "interface{ "+method+" }")
+	}
+	return ret
+}
+
+// wideMethods returns the methods of the interfaces wideInterfaces returns.
+func wideMethods(n int) []string {
+	ret := []string{}
+	for i := 1; i <= n; i++ {
+		ret = append(ret, fmt.Sprintf("W%d()", i))
+	}
+	return ret
+}
+
+// chain declares that each of the interfaces implies the next.
+func chain(ifaces []string) []Option {
+	ret := []Option{}
+	for i := 1; i < len(ifaces); i++ {
+		ret = append(ret, Implies(ifaces[i-1], ifaces[i]))
+	}
+	return ret
+}
+
+// loadTestdata loads the package in testdata/name.
+func loadTestdata(t *testing.T, name string) *packages.Package {
+	t.Helper()
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedTypes | packages.NeedName | packages.NeedSyntax | packages.NeedImports,
+		Dir:  "testdata/" + name,
+	}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs[0].Errors) > 0 {
+		t.Fatalf("loading testdata/%v: %v", name, pkgs[0].Errors)
+	}
+	return pkgs[0]
+}
+
+func TestPropagate(t *testing.T) {
+	pkg := loadTestdata(t, "wrappers")
+
+	for _, tc := range []struct {
+		name   string
+		sel    string
+		ifaces []string
+		opts   []Option
+		// contains are parts of the code we expect, and lacks ones we don't
+		contains []string
+		lacks    []string
+		// report, if set, is what we expect to be reported, besides the
+		// lines and the pruned interfaces
+		report *Report
+	}{
+		{
+			name:     "interface named like a literal",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{"interfaceFlusher"},
+			contains: []string{"_, ok0 := r.Reader.(interfaceFlusher)", "func (r *readWrapper) Flush() error {"},
+		},
+		{
+			name:     "literal with space",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{" interface { Flush() error }"},
+			contains: []string{"type ifacepropagateIface0 interface {", "_, ok0 := r.Reader.(ifacepropagateIface0)"},
+		},
+		{
+			name:     "regenerated",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{"interface{ Flush() error }"},
+			contains: []string{"type ifacepropagateIface0 interface {", "func (r *readWrapper) Flush() error {"},
+		},
+		{
+			name:     "type with unexported and excluded methods",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{pkg.PkgPath + "/other.Impl"},
+			opts:     []Option{PropagateTypes(), ExcludeMethods("Hide")},
+			contains: []string{"func (r *readWrapper) Close() error {"},
+			lacks:    []string{"Hide", "seal"},
+		},
+		{
+			name:     "unverified",
+			sel:      "f *flushWrapper.Writer",
+			ifaces:   []string{"interfaceFlusher"},
+			opts:     []Option{SkipVerify()},
+			contains: []string{"func (f *flushWrapper) Flush() error {"},
+		},
+		{
+			name:     "realized",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{"io.Closer", "io.Seeker", "io.WriterTo"},
+			opts:     []Option{RealizedBy("*file", "pipe")},
+			contains: []string{"case ok0 && ok1 && !ok2:", "case ok0 && !ok1 && !ok2:"},
+			report:   &Report{Cases: 2, UnrealizedCases: 6},
+		},
+		{
+			name:     "realized, nearest",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{"io.Closer", "io.Seeker", "io.WriterTo"},
+			opts:     []Option{RealizedBy("*file", "pipe"), FallbackTo(FallbackNearest)},
+			contains: []string{"case ok0 && ok1:", "case ok0:"},
+			lacks:    []string{"ok2"},
+			report:   &Report{Cases: 2, UnrealizedCases: 6},
+		},
+		{
+			name:     "realized, many interfaces",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   wideInterfaces(22),
+			opts:     []Option{RealizedBy("wide", "pipe")},
+			contains: []string{"case ok0 && ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7 && ok8 && ok9 && ok10 && ok11 && ok12 && ok13 && ok14 && ok15 && ok16 && ok17 && ok18 && ok19 && ok20 && ok21:"},
+			report:   &Report{Cases: 2, UnrealizedCases: 1<<22 - 2},
+		},
+		{
+			name:   "realized, many interfaces, no maximum",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(22),
+			opts:   []Option{RealizedBy("wide", "pipe"), MaxCases(0)},
+			report: &Report{Cases: 2, UnrealizedCases: 1<<22 - 2},
+		},
+		{
+			name:   "realized, as many cases as the maximum",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(22),
+			opts:   []Option{RealizedBy("wide", "pipe"), MaxCases(2)},
+			report: &Report{Cases: 2, UnrealizedCases: 1<<22 - 2},
+		},
+		{
+			name:     "infeasible",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{"io.Closer", "io.Seeker", "io.ReadSeekCloser"},
+			contains: []string{"case ok0 && ok1 && ok2:", "case !ok0 && !ok1 && !ok2:"},
+			lacks:    []string{"case ok0 && ok1 && !ok2:", "case !ok0 && !ok1 && ok2:"},
+			report:   &Report{Cases: 4, SkippedCases: 4},
+		},
+		{
+			name:   "infeasible and implied",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"io.Closer", "io.Seeker", "io.ReadSeekCloser"},
+			opts:   []Option{Implies("io.Closer", "io.Seeker")},
+			report: &Report{Cases: 3, SkippedCases: 3, ImpliedCases: 2},
+		},
+		{
+			name:     "implied",
+			sel:      "r *readWrapper.Reader",
+			ifaces:   []string{"io.Closer", "io.Seeker"},
+			opts:     []Option{Implies("io.Closer", "io.Seeker")},
+			contains: []string{"case ok0 && ok1:", "case !ok0 && ok1:", "case !ok0 && !ok1:"},
+			lacks:    []string{"case ok0 && !ok1:"},
+			report:   &Report{Cases: 3, ImpliedCases: 1},
+		},
+		{
+			name:   "implied, many interfaces",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(22),
+			opts:   chain(wideInterfaces(22)),
+			report: &Report{Cases: 23, ImpliedCases: 1<<22 - 23},
+		},
+		{
+			name:   "as many cases as the maximum",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(3),
+			opts:   []Option{MaxCases(8)},
+			report: &Report{Cases: 8},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := &Report{}
+			src, err := PropogateInterfaces(pkg, "propagateInterfaces", tc.sel, tc.ifaces, append(tc.opts, ReportTo(report))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.report != nil {
+				report.Lines, report.Pruned = 0, nil
+				if !reflect.DeepEqual(report, tc.report) {
+					t.Errorf("expected the report %+v, got %+v", tc.report, report)
+				}
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(src, want) {
+					t.Errorf("expected the code to contain %q, got:\n%s", want, src)
+				}
+			}
+			for _, unwanted := range tc.lacks {
+				if strings.Contains(src, unwanted) {
+					t.Errorf("expected the code not to contain %q, got:\n%s", unwanted, src)
+				}
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	pkg := loadTestdata(t, "wrappers")
+
+	for _, tc := range []struct {
+		name   string
+		sel    string
+		ifaces []string
+		opts   []Option
+		// err is part of the message of the error we expect, and as, if set,
+		// points to the type we expect it to be
+		err string
+		as  interface{}
+	}{
+		{
+			name:   "conflicting interfaces",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"Getter", "IntGetter"},
+			err:    `conflicting method "Get": ` + pkg.PkgPath + `.Getter declares it as func() string, but ` + pkg.PkgPath + `.IntGetter declares it as func() int`,
+		},
+		{
+			name:   "conflicting with the base interface",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"BadReader"},
+			err:    `conflicting method "Read": io.Reader declares it as func(p []byte) (n int, err error), but ` + pkg.PkgPath + `.BadReader declares it as func(p []byte) error`,
+		},
+		{
+			name:   "named field the struct doesn't implement",
+			sel:    "b *box[T, U].In",
+			ifaces: []string{"io.Closer"},
+			err:    "*box doesn't implement " + pkg.PkgPath + ".Source, which it wraps in 'box.In': it lacks Next; embed the field, or declare the methods",
+		},
+		{
+			name:   "named field only the pointer implements",
+			sel:    "v valueBox.r",
+			ifaces: []string{"io.Closer"},
+			err:    "valueBox doesn't implement io.Reader, which it wraps in 'valueBox.r': it lacks Read, which only *valueBox has",
+		},
+		{
+			name:   "unexported method",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{pkg.PkgPath + "/other.Sealed"},
+			err:    pkg.PkgPath + `/other.Sealed can't be propagated: its method "seal" is unexported, so only types in package "` + pkg.PkgPath + `/other" can implement it, not ones in "` + pkg.PkgPath + `"`,
+		},
+		{
+			name:   "unexported type",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{pkg.PkgPath + "/other.Hider"},
+			err:    pkg.PkgPath + `/other.Hider can't be propagated: its method "Hide" uses hidden, which is unexported in package "` + pkg.PkgPath + `/other" and so can't be referred to from "` + pkg.PkgPath + `"`,
+		},
+		{
+			name:   "unexported type of a type's method",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{pkg.PkgPath + "/other.Impl"},
+			opts:   []Option{PropagateTypes()},
+			err:    `method "Hide" of ` + pkg.PkgPath + `/other.Impl can't be propagated: it uses hidden, which is unexported in package "` + pkg.PkgPath + `/other" and so can't be referred to from "` + pkg.PkgPath + `"; exclude it to propagate the rest`,
+		},
+		{
+			name:   "comparable",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"comparable"},
+			err:    `"comparable" can't be propagated: constraints can't be used in type assertions or embedded in structs`,
+		},
+		{
+			name:   "constraint",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"Number"},
+			err:    `"Number" can't be propagated: it's a constraint because of its "~int | ~float64" element`,
+		},
+		{
+			name:   "embedded constraint",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"Stringer"},
+			err:    `"Stringer" can't be propagated: it's a constraint because of its "` + pkg.PkgPath + `.Number" element`,
+		},
+		{
+			name:   "constraint literal",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"interface{ comparable; Flush() error }"},
+			err:    `"interface{ comparable; Flush() error }" can't be propagated: it's a constraint because of its "comparable" element`,
+		},
+		{
+			name:   "generated code conflicting with the package",
+			sel:    "f *flushWrapper.Writer",
+			ifaces: []string{"interfaceFlusher"},
+			err:    "doesn't type-check together with package \"" + pkg.PkgPath + "\", be it because of what the package declares or a bug in ifacepropagate:\n\tmethod Flush of " + pkg.PkgPath + ".interfaceFlusher: field and method with the same name Flush",
+		},
+		{
+			name:   "type errors of methods",
+			sel:    "m *manyWrapper.Writer",
+			ifaces: []string{"Many"},
+			err:    "method M1 of " + pkg.PkgPath + ".Many: field and method with the same name M1 (at _ifacepropagate_generated.go:",
+		},
+		{
+			name:   "too many type errors to report",
+			sel:    "m *manyWrapper.Writer",
+			ifaces: []string{"Many"},
+			err:    "\n\tand 2 more",
+		},
+		{
+			name:   "selector without a space",
+			sel:    "r*readWrapper.Reader",
+			ifaces: []string{"io.Closer"},
+			err:    "struct selector must contain a space after the receiver name",
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "selector without a dot",
+			sel:    "r *readWrapper",
+			ifaces: []string{"io.Closer"},
+			err:    "but readWrapper did not have a dot",
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "selector with too few type parameters",
+			sel:    "b *box[T].In",
+			ifaces: []string{"io.Closer"},
+			err:    `"box" in package "` + pkg.PkgPath + `" has 2 type parameters, but the selector names 1`,
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "unparsable literal",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"interface{ Close( }"},
+			err:    `could not parse interface literal "interface{ Close( }"`,
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "unbalanced brackets",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"Source[int"},
+			err:    `"Source[int" has unbalanced brackets`,
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "method with a parenthesized receiver",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"(*os.File).Sync"},
+			opts:   []Option{PropagateMethods()},
+			err:    `method "(*os.File).Sync" must be of the form '<type>.<method>', such as '*net.TCPConn.SetReadBuffer': "(*os" is not a valid package path`,
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "implication of an interface not propagated",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"io.Closer"},
+			opts:   []Option{Implies("io.Closer", "io.Seeker")},
+			err:    "implication 'io.Closer=>io.Seeker' refers to io.Seeker, which is not one of the interfaces to propagate",
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "contradicting options",
+			sel:    "r *readWrapper.Reader",
+			ifaces: []string{"io.Closer"},
+			opts:   []Option{PropagateMethods(), PropagateTypes()},
+			err:    "methods and types can't be propagated at the same time",
+			as:     new(*ArgumentError),
+		},
+		{
+			name:   "missing struct",
+			sel:    "n *nope.Reader",
+			ifaces: []string{"io.Closer"},
+			err:    `could not find any struct named "nope" in package "` + pkg.PkgPath + `"`,
+			as:     new(*StructNotFoundError),
+		},
+		{
+			name:   "not a struct",
+			sel:    "g Getter.Get",
+			ifaces: []string{"io.Closer"},
+			err:    `"Getter" in package "` + pkg.PkgPath + `" is not a struct`,
+			as:     new(*StructNotFoundError),
+		},
+		{
+			name:   "missing field",
+			sel:    "c *counter.Writer",
+			ifaces: []string{"io.Closer"},
+			err:    `'counter' in package "` + pkg.PkgPath + `" has no field "Writer"`,
+			as:     new(*FieldNotFoundError),
+		},
+		{
+			name:   "method rather than field",
+			sel:    "c *counter.Count",
+			ifaces: []string{"io.Closer"},
+			err:    `'counter.Count' in package "` + pkg.PkgPath + `" is a method, not a field`,
+			as:     new(*FieldNotFoundError),
+		},
+		{
+			name:   "field of a non-struct",
+			sel:    "c *counter.n.x",
+			ifaces: []string{"io.Closer"},
+			err:    `'counter.n' in package "` + pkg.PkgPath + `" has no field "x"`,
+			as:     new(*FieldNotFoundError),
+		},
+		{
+			name:   "field not of an interface",
+			sel:    "c *counter.n",
+			ifaces: []string{"io.Closer"},
+			err:    `'counter.n' in package "` + pkg.PkgPath + `" is of type int, which is not an interface`,
+			as:     new(*NotInterfaceError),
+		},
+		{
+			name:   "missing interface",
+			sel:    "c *counter.Reader",
+			ifaces: []string{"io.Flusher"},
+			err:    `no interface named "Flusher" in package "io"`,
+			as:     new(*InterfaceNotFoundError),
+		},
+		{
+			name:   "not an interface",
+			sel:    "c *counter.Reader",
+			ifaces: []string{"notAType"},
+			err:    `"notAType" in package "` + pkg.PkgPath + `" is not an interface`,
+			as:     new(*InterfaceNotFoundError),
+		},
+		{
+			name:   "missing package",
+			sel:    "c *counter.Reader",
+			ifaces: []string{"example.invalid/nope.Closer"},
+			err:    `error loading package "example.invalid/nope"`,
+			as:     new(*PackageLoadError),
+		},
+		{
+			name:   "more cases than the maximum",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(3),
+			opts:   []Option{MaxCases(7)},
+			err:    "propagating 3 interfaces would take more than the maximum of 7 cases, which alone come to about 64 lines of code; to cut them down, propagate fewer interfaces, declare which of them imply others (Implies, -implies), name the types the interface holds (RealizedBy, -realized-by), or raise the maximum (MaxCases, -max-cases)",
+			as:     new(*TooManyCasesError),
+		},
+		{
+			name:   "more cases than the maximum, stopping to count",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(40),
+			opts:   []Option{MaxCases(256)},
+			err:    "propagating 40 interfaces would take more than the maximum of 256 cases",
+			as:     new(*TooManyCasesError),
+		},
+		{
+			name:   "more cases than the maximum, out of mostly infeasible ones",
+			sel:    "r *readWrapper.Reader",
+			ifaces: append(wideInterfaces(22), "interface{ "+strings.Join(wideMethods(22), "; ")+" }"),
+			opts:   []Option{MaxCases(256)},
+			err:    "propagating 23 interfaces would take more than the maximum of 256 cases",
+			as:     new(*TooManyCasesError),
+		},
+		{
+			name:   "more realized cases than the maximum",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(22),
+			opts:   []Option{RealizedBy("wide", "pipe"), MaxCases(1)},
+			err:    "propagating 22 interfaces would take more than the maximum of 1 case, which alone come to about 126 lines of code; to cut them down, propagate fewer interfaces, declare which of them imply others (Implies, -implies), name fewer of the types the interface holds (RealizedBy, -realized-by), or raise the maximum (MaxCases, -max-cases)",
+			as:     new(*TooManyCasesError),
+		},
+		{
+			name:   "more interfaces than fit a bitmask",
+			sel:    "r *readWrapper.Reader",
+			ifaces: wideInterfaces(maxInterfaces + 1),
+			opts:   []Option{RealizedBy("wide")},
+			err:    fmt.Sprintf("can't propagate more than %d interfaces at once, got %d", maxInterfaces, maxInterfaces+1),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := PropogateInterfaces(pkg, "propagateInterfaces", tc.sel, tc.ifaces, tc.opts...)
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tc.err)
+			}
+			if !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected an error containing %q, got %q", tc.err, err)
+			}
+			if tc.as != nil && !errors.As(err, tc.as) {
+				t.Errorf("expected an error of type %T, got %T", tc.as, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package ifacepropagate
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// always stands in for an interface which was pruned because the base
+// interface has all of its methods, i.e. which every value implements.
+const always = -1
+
+// rule is an implication between two of the interfaces we propagate, by
+// their index, or always.
+type rule struct {
+	from, to int
+}
+
+// resolveImplications returns the rules for the given implications. given
+// are the interfaces in the order they were given in, entries are how they
+// were written, and kept and pruned are what pruneInterfaces made of them.
+func resolveImplications(implications []implication, base *iface, entries []string, given, kept []*iface, pruned []PrunedInterface) ([]rule, error) {
+	index := func(ifc *iface) int {
+		for i, k := range kept {
+			if k == ifc {
+				return i
+			}
+		}
+		for _, p := range pruned {
+			if p.Interface != ifc.String() {
+				continue
+			}
+			if p.SubsumedBy == base.String() {
+				return always
+			}
+			for i, k := range kept {
+				if k.String() == p.SubsumedBy {
+					return i
+				}
+			}
+		}
+		panic(fmt.Sprintf("%v was neither kept nor pruned", ifc))
+	}
+	lookup := func(imp implication, s string) (int, error) {
+		s = strings.TrimSpace(s)
+		for i, ifc := range given {
+			if strings.TrimSpace(entries[i]) == s || ifc.String() == s {
+				return index(ifc), nil
+			}
+		}
+		return 0, &ArgumentError{
+			Arg: imp.from + "=>" + imp.to,
+			Err: fmt.Errorf("implication '%v=>%v' refers to %v, which is not one of the interfaces to propagate", imp.from, imp.to, s),
+		}
+	}
+
+	rules := make([]rule, 0, len(implications))
+	for _, imp := range implications {
+		from, err := lookup(imp, imp.from)
+		if err != nil {
+			return nil, err
+		}
+		to, err := lookup(imp, imp.to)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule{from, to})
+	}
+	return rules, nil
+}
+
+// consistent reports whether the given permutation of the interfaces obeys
+// all of the rules.
+func consistent(rules []rule, perm int) bool {
+	enabled := func(i int) bool {
+		return i == always || perm>>i&0x1 == 1
+	}
+	for _, r := range rules {
+		if enabled(r.from) && !enabled(r.to) {
+			return false
+		}
+	}
+	return true
+}
+
+// implied returns perm along with all the interfaces the rules say its
+// interfaces imply, directly or not.
+func implied(rules []rule, perm int) int {
+	for changed := true; changed; {
+		changed = false
+		for _, r := range rules {
+			if r.to == always || perm>>r.to&0x1 == 1 {
+				continue
+			}
+			if r.from == always || perm>>r.from&0x1 == 1 {
+				perm |= 1 << r.to
+				changed = true
+			}
+		}
+	}
+	return perm
+}
+
+// countConsistent returns how many permutations of n interfaces obey all of
+// the rules. The interfaces the rules don't tie to undecided ones are counted
+// all at once, rather than one permutation at a time.
+func countConsistent(n int, rules []rule) int {
+	var count func(i, ones, zeros int) int
+	count = func(i, ones, zeros int) int {
+		undecided := (1<<(i+1) - 1) &^ ones
+		free := true
+		for _, r := range rules {
+			if r.from != always && r.to != always && r.from != r.to &&
+				undecided>>r.from&0x1 == 1 && ones>>r.to&0x1 == 0 {
+				free = false
+				break
+			}
+		}
+		if free {
+			return 1 << bits.OnesCount(uint(undecided))
+		}
+
+		bit := 1 << i
+		if ones&bit != 0 {
+			return count(i-1, ones, zeros)
+		}
+		ret := count(i-1, ones, zeros|bit)
+		if with := implied(rules, ones|bit); with&zeros == 0 {
+			ret += count(i-1, with, zeros)
+		}
+		return ret
+	}
+	return count(n-1, implied(rules, 0), 0)
+}
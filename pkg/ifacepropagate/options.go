@@ -0,0 +1,154 @@
+package ifacepropagate
+
+// Option configures optional behavior of PropogateInterfaces.
+type Option func(*options)
+
+type options struct {
+	methods  bool
+	types    bool
+	exclude  []string
+	noVerify bool
+	report   *Report
+	implies  []implication
+	realized []string
+	fallback Fallback
+	maxCases int
+}
+
+type implication struct {
+	from, to string
+}
+
+// PropagateMethods makes PropogateInterfaces treat each entry of
+// wrappedInterfaces as a single method, rather than an interface. Every
+// method is propagated on its own, regardless of which other methods the
+// type declaring it has.
+//
+// Methods are given as '<type>.<method>', where the type may be an interface
+// or a concrete type, such as "*net.TCPConn.SetReadBuffer" or
+// "net/http.Flusher.Flush".
+func PropagateMethods() Option {
+	return func(o *options) {
+		o.methods = true
+	}
+}
+
+// PropagateTypes makes PropogateInterfaces treat each entry of
+// wrappedInterfaces as a type, such as "*net.TCPConn", and propagate the
+// methods those types have beyond the wrapped interface.
+//
+// Methods are grouped by which of the types have them, so for
+// "*net.TCPConn,*crypto/tls.Conn" the methods both of them have are one
+// capability, and the ones only either of them has are another.
+func PropagateTypes() Option {
+	return func(o *options) {
+		o.types = true
+	}
+}
+
+// ExcludeMethods hides the named methods from the ones PropagateTypes
+// propagates.
+func ExcludeMethods(names ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, names...)
+	}
+}
+
+// SkipVerify makes PropogateInterfaces return the generated code without
+// type-checking it along with the rest of the package first. That's only
+// useful for packages go/types can't check on its own, such as ones using
+// cgo.
+func SkipVerify() Option {
+	return func(o *options) {
+		o.noVerify = true
+	}
+}
+
+// Implies declares that every value which implements the interface from
+// implements the interface to as well, so that PropogateInterfaces can leave
+// out the cases for values which don't. Values which turn out to break the
+// rule anyway get only the base interface.
+//
+// Both interfaces are written as they were given in wrappedInterfaces, such
+// as Implies("net/http.Pusher", "net/http.Flusher").
+func Implies(from, to string) Option {
+	return func(o *options) {
+		o.implies = append(o.implies, implication{from, to})
+	}
+}
+
+// RealizedBy declares that the wrapped interface only ever holds values of
+// the given types, such as "*net.TCPConn" or "*crypto/tls.Conn", so that
+// PropogateInterfaces generates cases for just the combinations of
+// interfaces those implement. What values of other types get is up to the
+// Fallback.
+func RealizedBy(types ...string) Option {
+	return func(o *options) {
+		o.realized = append(o.realized, types...)
+	}
+}
+
+// Fallback decides what values get if they implement a combination of
+// interfaces PropogateInterfaces left out, because of RealizedBy or Implies.
+type Fallback int
+
+const (
+	// FallbackBase gives them just the base interface.
+	FallbackBase Fallback = iota
+	// FallbackNearest gives them the largest combination there is a case for,
+	// out of the interfaces they do implement.
+	FallbackNearest
+)
+
+// FallbackTo sets the Fallback, which is FallbackBase by default.
+func FallbackTo(f Fallback) Option {
+	return func(o *options) {
+		o.fallback = f
+	}
+}
+
+// MaxCases makes PropogateInterfaces fail with a *TooManyCasesError rather
+// than generate more than n cases, each of which is a struct type of its own.
+// There's no maximum by default, nor if n is 0.
+func MaxCases(n int) Option {
+	return func(o *options) {
+		o.maxCases = n
+	}
+}
+
+// ReportTo makes PropogateInterfaces describe what it generated in r.
+func ReportTo(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// Report describes the code PropogateInterfaces generated.
+type Report struct {
+	// Cases is the number of cases generated, not counting the default one.
+	Cases int
+	// Lines is the number of lines of code generated.
+	Lines int
+	// Pruned are the interfaces which were left out, since they would have
+	// added no methods.
+	Pruned []PrunedInterface
+	// SkippedCases is the number of combinations of interfaces we generated
+	// no case for, since no value can implement exactly those, out of the
+	// ones the rules given with Implies allow.
+	SkippedCases int
+	// ImpliedCases is the number of combinations of interfaces we generated
+	// no case for, since they break the rules given with Implies.
+	ImpliedCases int
+	// UnrealizedCases is the number of combinations of interfaces we
+	// generated no case for, since none of the types given with RealizedBy
+	// implement them.
+	UnrealizedCases int
+}
+
+// PrunedInterface is an interface PropogateInterfaces left out.
+type PrunedInterface struct {
+	Interface string
+	// SubsumedBy is the base interface, if it has all of the interface's
+	// methods, or else the propagated interface with the same methods.
+	SubsumedBy string
+}
@@ -0,0 +1,80 @@
+package ifacepropagate
+
+// pruneInterfaces drops the interfaces which would add nothing to the
+// struct we return: those whose methods the base interface has all of, and
+// those with the same methods, besides the base's, as one before them.
+func pruneInterfaces(base *iface, ifaces []*iface) ([]*iface, []PrunedInterface) {
+	kept := []*iface{}
+	pruned := []PrunedInterface{}
+	keptMethods := []map[string]struct{}{}
+
+next:
+	for _, ifc := range ifaces {
+		methods := methodNames(ifc)
+		if covered(methods, methodNames(base)) {
+			pruned = append(pruned, PrunedInterface{Interface: ifc.String(), SubsumedBy: base.String()})
+			continue
+		}
+		for i, other := range kept {
+			if covered(methods, keptMethods[i], methodNames(base)) && covered(keptMethods[i], methods, methodNames(base)) {
+				pruned = append(pruned, PrunedInterface{Interface: ifc.String(), SubsumedBy: other.String()})
+				continue next
+			}
+		}
+		kept = append(kept, ifc)
+		keptMethods = append(keptMethods, methods)
+	}
+	return kept, pruned
+}
+
+// feasible reports whether a value could implement exactly the interfaces in
+// the given permutation, besides the base. It can't if the methods of the
+// ones it implements add up to all of another one's methods. base and ifaces
+// are the methodNames of the interfaces.
+func feasible(base map[string]struct{}, ifaces []map[string]struct{}, perm int) bool {
+	return covering(base, ifaces, perm) == perm
+}
+
+// covering returns perm along with the interfaces whose methods the base and
+// perm's interfaces add up to, i.e. which any value implementing those
+// implements too.
+func covering(base map[string]struct{}, ifaces []map[string]struct{}, perm int) int {
+	provided := []map[string]struct{}{base}
+	for i, methods := range ifaces {
+		if perm>>i&0x1 == 1 {
+			provided = append(provided, methods)
+		}
+	}
+	ret := perm
+	for i, methods := range ifaces {
+		if perm>>i&0x1 == 0 && covered(methods, provided...) {
+			ret |= 1 << i
+		}
+	}
+	return ret
+}
+
+// covered reports whether each of the methods is in one of the sets.
+func covered(methods map[string]struct{}, sets ...map[string]struct{}) bool {
+	for name := range methods {
+		found := false
+		for _, set := range sets {
+			if _, ok := set[name]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func methodNames(ifc *iface) map[string]struct{} {
+	ret := make(map[string]struct{}, ifc.obj.NumMethods())
+	for i := 0; i < ifc.obj.NumMethods(); i++ {
+		ret[ifc.obj.Method(i).Name()] = struct{}{}
+	}
+	return ret
+}
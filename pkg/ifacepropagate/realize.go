@@ -0,0 +1,53 @@
+package ifacepropagate
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// realizedPerms returns the permutations of ifaces the given types implement,
+// i.e. the only ones values of those types can get to.
+func realizedPerms(pkg *packages.Package, sel *structSel, typeSpecs []string, ifaces []*iface) (map[int]bool, error) {
+	ret := map[int]bool{}
+	for _, spec := range typeSpecs {
+		spec = strings.TrimSpace(spec)
+		t, err := parseTypeExpr(pkg, sel, spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q: %w", spec, err)
+		}
+		if !implements(t, sel.iface) {
+			return nil, fmt.Errorf("%v doesn't implement %v, so it can't be what %v wraps", spec, sel.iface, sel.structName)
+		}
+		perm := 0
+		for i, ifc := range ifaces {
+			if implements(t, ifc) {
+				perm |= 1 << i
+			}
+		}
+		ret[perm] = true
+	}
+	return ret, nil
+}
+
+// implements reports whether t has all of the interface's methods. Unlike
+// types.Implements, it doesn't mind the two being loaded separately.
+func implements(t types.Type, ifc *iface) bool {
+	return len(unimplemented(t, ifc)) == 0
+}
+
+// unimplemented returns the interface's methods which t lacks, or has with a
+// different signature.
+func unimplemented(t types.Type, ifc *iface) []*types.Func {
+	ret := []*types.Func{}
+	for i := 0; i < ifc.obj.NumMethods(); i++ {
+		want := ifc.obj.Method(i)
+		method := lookupMethod(t, want.Name())
+		if method == nil || !identicalSignatures(methodSignature(method), want.Type()) {
+			ret = append(ret, want)
+		}
+	}
+	return ret
+}
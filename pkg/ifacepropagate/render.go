@@ -0,0 +1,223 @@
+package ifacepropagate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// typeRenderer renders types as expressions usable from within the package
+// we're generating code for, and keeps track of every package it had to
+// qualify a type with so that we can import them all.
+type typeRenderer struct {
+	pkg     *types.Package
+	imports map[string]importSpec
+	// reserved holds names which no import may use, in addition to the ones in
+	// the package scope
+	reserved map[string]struct{}
+	// typeParams holds the names we refer to type parameters by, if they
+	// differ from their declared names
+	typeParams map[*types.TypeParam]string
+}
+
+type importSpec struct {
+	// name is the package's own name, local the one we refer to it by
+	name  string
+	local string
+}
+
+func newTypeRenderer(pkg *types.Package, reserved ...string) *typeRenderer {
+	r := &typeRenderer{
+		pkg:        pkg,
+		imports:    map[string]importSpec{},
+		reserved:   map[string]struct{}{},
+		typeParams: map[*types.TypeParam]string{},
+	}
+	for _, name := range reserved {
+		r.reserved[name] = struct{}{}
+	}
+	return r
+}
+
+// renameTypeParams makes the renderer refer to the given type parameters by
+// the given names, rather than the ones they were declared with.
+func (r *typeRenderer) renameTypeParams(tparams *types.TypeParamList, names []string) {
+	for i := 0; i < tparams.Len(); i++ {
+		r.typeParams[tparams.At(i)] = names[i]
+	}
+}
+
+// importName records that the generated file needs to import the given
+// package, and returns the name it may be referred to by. That's the
+// package's own name unless it's already taken by another import or by an
+// identifier in the package, in which case the import gets a unique alias.
+func (r *typeRenderer) importName(path, name string) string {
+	if path == r.pkg.Path() {
+		return ""
+	}
+	if spec, ok := r.imports[path]; ok {
+		return spec.local
+	}
+
+	local := name
+	for suffix := 1; !r.nameFree(local); suffix++ {
+		local = fmt.Sprintf("%s%d", name, suffix)
+	}
+	r.imports[path] = importSpec{name: name, local: local}
+	return local
+}
+
+func (r *typeRenderer) nameFree(name string) bool {
+	if _, taken := r.reserved[name]; taken {
+		return false
+	}
+	for _, spec := range r.imports {
+		if spec.local == name {
+			return false
+		}
+	}
+	return r.pkg.Scope().Lookup(name) == nil
+}
+
+func (r *typeRenderer) qualifier(p *types.Package) string {
+	return r.importName(p.Path(), p.Name())
+}
+
+// expr renders the given type, i.e. 'map[string]*foo/bar/baz.Qux' as
+// 'map[string]*baz.Qux'.
+func (r *typeRenderer) expr(t types.Type) ast.Expr {
+	switch t := t.(type) {
+	case *types.Basic:
+		return ast.NewIdent(t.Name())
+	case *types.Named:
+		return r.typeName(t.Obj(), t.TypeArgs())
+	case *types.Alias:
+		return r.typeName(t.Obj(), t.TypeArgs())
+	case *types.TypeParam:
+		if name, ok := r.typeParams[t]; ok {
+			return ast.NewIdent(name)
+		}
+		return ast.NewIdent(t.Obj().Name())
+	case *types.Pointer:
+		return &ast.StarExpr{X: r.expr(t.Elem())}
+	case *types.Slice:
+		return &ast.ArrayType{Elt: r.expr(t.Elem())}
+	case *types.Array:
+		return &ast.ArrayType{
+			Len: &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(t.Len(), 10)},
+			Elt: r.expr(t.Elem()),
+		}
+	case *types.Map:
+		return &ast.MapType{Key: r.expr(t.Key()), Value: r.expr(t.Elem())}
+	case *types.Chan:
+		dir := ast.SEND | ast.RECV
+		switch t.Dir() {
+		case types.SendOnly:
+			dir = ast.SEND
+		case types.RecvOnly:
+			dir = ast.RECV
+		}
+		return &ast.ChanType{Dir: dir, Value: r.expr(t.Elem())}
+	case *types.Signature:
+		return r.funcType(t)
+	case *types.Struct:
+		if t.NumFields() == 0 {
+			// without positions, the printer would put the braces on separate lines
+			return ast.NewIdent("struct{}")
+		}
+		fields := []*ast.Field{}
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			f := &ast.Field{Type: r.expr(field.Type())}
+			if !field.Embedded() {
+				f.Names = []*ast.Ident{ast.NewIdent(field.Name())}
+			}
+			if tag := t.Tag(i); tag != "" {
+				f.Tag = &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(tag)}
+			}
+			fields = append(fields, f)
+		}
+		return &ast.StructType{Fields: &ast.FieldList{List: fields}}
+	case *types.Interface:
+		if t.NumEmbeddeds() == 0 && t.NumExplicitMethods() == 0 {
+			return ast.NewIdent("interface{}")
+		}
+		methods := []*ast.Field{}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			methods = append(methods, &ast.Field{Type: r.expr(t.EmbeddedType(i))})
+		}
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			m := t.ExplicitMethod(i)
+			methods = append(methods, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(m.Name())},
+				Type:  r.funcType(m.Type().(*types.Signature)),
+			})
+		}
+		return &ast.InterfaceType{Methods: &ast.FieldList{List: methods}}
+	}
+	// Anything else (i.e. unions in constraints) can't appear in a method
+	// signature; the printer doesn't care though, so emit it as is.
+	return ast.NewIdent(types.TypeString(t, r.qualifier))
+}
+
+func (r *typeRenderer) typeName(obj *types.TypeName, targs *types.TypeList) ast.Expr {
+	var ret ast.Expr = ast.NewIdent(obj.Name())
+	if obj.Pkg() != nil {
+		if name := r.qualifier(obj.Pkg()); name != "" {
+			ret = &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent(obj.Name())}
+		}
+	}
+	if targs.Len() == 0 {
+		return ret
+	}
+	indices := make([]ast.Expr, 0, targs.Len())
+	for i := 0; i < targs.Len(); i++ {
+		indices = append(indices, r.expr(targs.At(i)))
+	}
+	return &ast.IndexListExpr{X: ret, Indices: indices}
+}
+
+func (r *typeRenderer) funcType(sig *types.Signature) *ast.FuncType {
+	fields := func(tuple *types.Tuple, variadic bool) *ast.FieldList {
+		ret := &ast.FieldList{}
+		for i := 0; i < tuple.Len(); i++ {
+			v := tuple.At(i)
+			f := &ast.Field{Type: r.expr(v.Type())}
+			if variadic && i == tuple.Len()-1 {
+				f.Type = &ast.Ellipsis{Elt: r.expr(v.Type().(*types.Slice).Elem())}
+			}
+			if v.Name() != "" {
+				f.Names = []*ast.Ident{ast.NewIdent(v.Name())}
+			}
+			ret.List = append(ret.List, f)
+		}
+		return ret
+	}
+	return &ast.FuncType{
+		Params:  fields(sig.Params(), sig.Variadic()),
+		Results: fields(sig.Results(), false),
+	}
+}
+
+// addImports adds all recorded imports to the given file, aliasing those
+// which can't be referred to by their package name.
+func (r *typeRenderer) addImports(fset *token.FileSet, f *ast.File) {
+	paths := make([]string, 0, len(r.imports))
+	for path := range r.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		spec := r.imports[path]
+		if spec.local == spec.name {
+			astutil.AddImport(fset, f, path)
+		} else {
+			astutil.AddNamedImport(fset, f, spec.local, path)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Package other declares what the wrappers package can't refer to.
+package other
+
+// Sealed can only be implemented in this package
+type Sealed interface {
+	Seal()
+	seal()
+}
+
+type hidden struct{}
+
+type Hider interface {
+	Hide() hidden
+}
+
+type Impl struct{}
+
+func (Impl) Read(p []byte) (int, error) { return 0, nil }
+
+func (Impl) Close() error { return nil }
+
+func (Impl) Hide() hidden { return hidden{} }
+
+func (Impl) seal() {}
@@ -0,0 +1,169 @@
+// Package wrappers holds the structs and interfaces gen_test.go generates
+// code for, or fails to.
+package wrappers
+
+import (
+	"io"
+)
+
+type readWrapper struct {
+	io.Reader
+}
+
+type Getter interface {
+	Get() string
+}
+
+type IntGetter interface {
+	Get() int
+}
+
+// BadReader disagrees with io.Reader on Read
+type BadReader interface {
+	Read(p []byte) error
+}
+
+type Source[T any] interface {
+	Next() T
+}
+
+// box holds its source in a named field, but doesn't implement it
+type box[T, U any] struct {
+	In  Source[T]
+	Out U
+}
+
+type valueBox struct {
+	r io.Reader
+}
+
+func (v *valueBox) Read(p []byte) (int, error) {
+	return v.r.Read(p)
+}
+
+// interfaceFlusher is named like an interface literal starts
+type interfaceFlusher interface {
+	Flush() error
+}
+
+type Number interface {
+	~int | ~float64
+}
+
+// Stringer is only a constraint because of what it embeds
+type Stringer interface {
+	Number
+	String() string
+}
+
+// flushWrapper's field gets in the way of the Flush method we'd generate
+type flushWrapper struct {
+	io.Writer
+	Flush bool
+}
+
+// Many and manyWrapper conflict more than verify reports on
+type Many interface {
+	M1()
+	M2()
+	M3()
+	M4()
+	M5()
+	M6()
+	M7()
+	M8()
+	M9()
+	M10()
+	M11()
+	M12()
+}
+
+type manyWrapper struct {
+	io.Writer
+	M1  bool
+	M2  bool
+	M3  bool
+	M4  bool
+	M5  bool
+	M6  bool
+	M7  bool
+	M8  bool
+	M9  bool
+	M10 bool
+	M11 bool
+	M12 bool
+}
+
+type counter struct {
+	io.Reader
+	n int
+}
+
+func (c *counter) Count() int {
+	return c.n
+}
+
+var notAType io.Reader
+
+type file struct{}
+
+func (*file) Read(p []byte) (int, error) { return 0, io.EOF }
+
+func (*file) Close() error { return nil }
+
+func (*file) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+
+type pipe struct{}
+
+func (pipe) Read(p []byte) (int, error) { return 0, io.EOF }
+
+func (pipe) Close() error { return nil }
+
+// wide has more methods than the combinations of which we could go through
+type wide struct{}
+
+func (wide) Read(p []byte) (int, error) { return 0, io.EOF }
+
+func (wide) W1() {}
+
+func (wide) W2() {}
+
+func (wide) W3() {}
+
+func (wide) W4() {}
+
+func (wide) W5() {}
+
+func (wide) W6() {}
+
+func (wide) W7() {}
+
+func (wide) W8() {}
+
+func (wide) W9() {}
+
+func (wide) W10() {}
+
+func (wide) W11() {}
+
+func (wide) W12() {}
+
+func (wide) W13() {}
+
+func (wide) W14() {}
+
+func (wide) W15() {}
+
+func (wide) W16() {}
+
+func (wide) W17() {}
+
+func (wide) W18() {}
+
+func (wide) W19() {}
+
+func (wide) W20() {}
+
+func (wide) W21() {}
+
+func (wide) W22() {}
@@ -0,0 +1,28 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package wrappers
+
+import "io"
+
+// What we generated before gets replaced, so neither its methods nor its
+// types may keep us from generating them again
+
+type ifacepropagateIface0 interface {
+	Flush() error
+}
+
+func (r *readWrapper) propagateInterfaces() io.Reader {
+	_, ok0 := r.Reader.(ifacepropagateIface0)
+	switch {
+	case ok0:
+		return struct {
+			io.Reader
+			ifacepropagateIface0
+		}{r, r}
+	default:
+		return r
+	}
+}
+func (r *readWrapper) Flush() error {
+	return r.Reader.(ifacepropagateIface0).Flush()
+}
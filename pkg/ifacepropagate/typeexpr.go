@@ -0,0 +1,349 @@
+package ifacepropagate
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// parseTypeExpr resolves a type written the way interfaces are on the command
+// line, i.e. 'string', 'Event' for a type in the target package, or
+// '[]*net/http.Request'. The struct's type parameters may be referred to by
+// the names the struct selector gave them.
+//
+// Only the type forms which commonly appear as type arguments are supported:
+// named types (which may be instantiated themselves), pointers, slices and
+// maps.
+func parseTypeExpr(pkg *packages.Package, sel *structSel, s string) (types.Type, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("empty type")}
+	case strings.HasPrefix(s, "*"):
+		elem, err := parseTypeExpr(pkg, sel, s[1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewPointer(elem), nil
+	case strings.HasPrefix(s, "[]"):
+		elem, err := parseTypeExpr(pkg, sel, s[2:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewSlice(elem), nil
+	case strings.HasPrefix(s, "map["):
+		end := matchingBracket(s, len("map"))
+		if end == -1 {
+			return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("%q is missing a closing ']'", s)}
+		}
+		key, err := parseTypeExpr(pkg, sel, s[len("map["):end])
+		if err != nil {
+			return nil, err
+		}
+		elem, err := parseTypeExpr(pkg, sel, s[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewMap(key, elem), nil
+	}
+
+	name, typeArgs, err := splitTypeArgs(s)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := lookupTypeName(pkg, sel, name)
+	if err != nil {
+		return nil, err
+	}
+	return instantiate(pkg, sel, obj, typeArgs)
+}
+
+// isInterfaceLiteral reports whether s is an interface literal, rather than
+// the name of an interface, which may well start with 'interface' too.
+func isInterfaceLiteral(s string) bool {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "interface") {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(s[len("interface"):]), "{")
+}
+
+// parseInterfaceLiteral type-checks an interface literal, such as
+// 'interface{ CloseWrite() error }', in the scope of the package. Besides the
+// package's own types, it may refer to any package the package imports, or
+// any package whose import path is its name, such as 'io' or 'time'.
+func parseInterfaceLiteral(pkg *packages.Package, s string) (*iface, error) {
+	s = strings.TrimSpace(s)
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("could not parse interface literal %q: %w", s, err)}
+	}
+	if _, ok := expr.(*ast.InterfaceType); !ok {
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("%q is not an interface literal", s)}
+	}
+
+	// Evaluate the literal in a copy of the package's scope, with the
+	// packages it refers to imported.
+	scopePkg := types.NewPackage(pkg.PkgPath, pkg.Name)
+	for _, name := range pkg.Types.Scope().Names() {
+		scopePkg.Scope().Insert(pkg.Types.Scope().Lookup(name))
+	}
+	imports := map[string]*types.Package{}
+	for _, imp := range pkg.Types.Imports() {
+		imports[imp.Name()] = imp
+	}
+	var importErr error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok || scopePkg.Scope().Lookup(x.Name) != nil {
+			return true
+		}
+		imp, ok := imports[x.Name]
+		if !ok {
+			loaded, err := loadPackage(pkg, x.Name)
+			if err != nil || loaded.Types == nil || loaded.Types.Name() != x.Name {
+				importErr = fmt.Errorf("interface literal %q refers to %q, which is neither imported by package %q nor a package path", s, x.Name, pkg.PkgPath)
+				return false
+			}
+			imp = loaded.Types
+		}
+		scopePkg.Scope().Insert(types.NewPkgName(token.NoPos, scopePkg, x.Name, imp))
+		return true
+	})
+	if importErr != nil {
+		return nil, importErr
+	}
+
+	tv, err := types.Eval(token.NewFileSet(), scopePkg, token.NoPos, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interface literal %q: %w", s, err)
+	}
+	if err := checkBasicInterface(s, tv.Type.(*types.Interface)); err != nil {
+		return nil, err
+	}
+	return &iface{
+		pkgName:          pkg.Name,
+		pkgPath:          pkg.PkgPath,
+		isCurrentPackage: true,
+		obj:              tv.Type.(*types.Interface),
+		literal:          s,
+	}, nil
+}
+
+// parseMethod returns a single method interface for a method given as
+// '<type>.<method>', such as '*net.TCPConn.SetReadBuffer'.
+func parseMethod(pkg *packages.Package, sel *structSel, s string) (*iface, error) {
+	s = strings.TrimSpace(s)
+	lastDot := strings.LastIndex(s, ".")
+	if lastDot == -1 {
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("method %q must be of the form '<type>.<method>'", s)}
+	}
+	typeSpec, methodName := s[:lastDot], s[lastDot+1:]
+	t, err := parseTypeExpr(pkg, sel, typeSpec)
+	if argErr := (*ArgumentError)(nil); errors.As(err, &argErr) {
+		return nil, &ArgumentError{Arg: s, Err: fmt.Errorf("method %q must be of the form '<type>.<method>', such as '*net.TCPConn.SetReadBuffer': %w", s, err)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid type for method %q: %w", s, err)
+	}
+
+	method := lookupMethod(t, methodName)
+	if method == nil {
+		if _, isPtr := t.(*types.Pointer); !isPtr && !types.IsInterface(t) {
+			if lookupMethod(types.NewPointer(t), methodName) != nil {
+				return nil, fmt.Errorf("%v has no method %q, but *%v does", typeSpec, methodName, typeSpec)
+			}
+		}
+		return nil, fmt.Errorf("%v has no method %q", typeSpec, methodName)
+	}
+
+	sig := methodSignature(method)
+	obj := types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, method.Pkg(), methodName, sig)}, nil).Complete()
+	return &iface{
+		pkgName:          pkg.Name,
+		pkgPath:          pkg.PkgPath,
+		isCurrentPackage: true,
+		obj:              obj,
+		literal:          s,
+		method:           methodName,
+	}, nil
+}
+
+// lookupMethod returns the method with the given name in t's method set, or
+// nil if there's none.
+func lookupMethod(t types.Type, name string) *types.Func {
+	mset := types.NewMethodSet(t)
+	for i := 0; i < mset.Len(); i++ {
+		if obj := mset.At(i).Obj(); obj.Name() == name {
+			return obj.(*types.Func)
+		}
+	}
+	return nil
+}
+
+// methodSignature returns the method's signature without its receiver, which
+// doesn't belong in an interface's method, nor in comparing it with one.
+func methodSignature(method *types.Func) *types.Signature {
+	sig := method.Type().(*types.Signature)
+	return types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic())
+}
+
+// lookupTypeName finds the type with the given, possibly package qualified,
+// name.
+func lookupTypeName(pkg *packages.Package, sel *structSel, name string) (*types.TypeName, error) {
+	var obj types.Object
+	if lastDot := strings.LastIndex(name, "."); lastDot != -1 {
+		pkgPath, typeName := name[:lastDot], name[lastDot+1:]
+		if !isPackagePath(pkgPath) {
+			return nil, &ArgumentError{Arg: name, Err: fmt.Errorf("%q is not a valid package path", pkgPath)}
+		}
+		typePkg, err := loadPackage(pkg, pkgPath)
+		if err != nil {
+			return nil, err
+		}
+		obj = typePkg.Types.Scope().Lookup(typeName)
+	} else {
+		for i, tparam := range sel.typeParamNames {
+			if tparam == name {
+				return sel.named.TypeParams().At(i).Obj(), nil
+			}
+		}
+		obj = pkg.Types.Scope().Lookup(name)
+		if obj == nil {
+			obj = types.Universe.Lookup(name)
+		}
+	}
+
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("no type named %q", name)
+	}
+	return tn, nil
+}
+
+// isPackagePath reports whether s could be an import path, i.e. whether it's
+// made of only letters, digits and the punctuation they allow. Anything else,
+// such as the parentheses of '(*os.File).Sync', is a typo rather than a
+// package we'd fail to load.
+func isPackagePath(s string) bool {
+	if s == "" || strings.HasPrefix(s, "/") || strings.HasSuffix(s, "/") {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case strings.ContainsRune("-._~/+", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// instantiate returns the type declared by obj, instantiated with the given
+// type arguments if it's generic.
+func instantiate(pkg *packages.Package, sel *structSel, obj *types.TypeName, typeArgs []string) (types.Type, error) {
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		if len(typeArgs) > 0 {
+			return nil, fmt.Errorf("%v is not generic, but was given type arguments", obj.Name())
+		}
+		return obj.Type(), nil
+	}
+	if len(typeArgs) == 0 {
+		return nil, fmt.Errorf("%v is generic, so it must be instantiated, i.e. '%v[T]'", obj.Name(), obj.Name())
+	}
+
+	targs := make([]types.Type, 0, len(typeArgs))
+	for _, arg := range typeArgs {
+		targ, err := parseTypeExpr(pkg, sel, arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type argument for %v: %w", obj.Name(), err)
+		}
+		targs = append(targs, targ)
+	}
+	inst, err := types.Instantiate(nil, named, targs, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not instantiate %v: %w", obj.Name(), err)
+	}
+	return inst, nil
+}
+
+// splitTypeArgs splits 'foo/bar.Baz[K, []V]' into 'foo/bar.Baz' and its type
+// arguments, 'K' and '[]V'.
+func splitTypeArgs(s string) (string, []string, error) {
+	open := strings.Index(s, "[")
+	if open == -1 {
+		return s, nil, nil
+	}
+	if matchingBracket(s, open) != len(s)-1 {
+		return "", nil, &ArgumentError{Arg: s, Err: fmt.Errorf("%q has unbalanced brackets", s)}
+	}
+	return s[:open], splitTopLevel(s[open+1 : len(s)-1]), nil
+}
+
+// matchingBracket returns the index of the ']' closing the '[' at s[open], or
+// -1 if there is none.
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on every comma that isn't within brackets.
+func splitTopLevel(s string) []string {
+	ret := []string{}
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				ret = append(ret, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, s[start:])
+}
+
+// loadPackage loads the package with the given import path, reusing pkg if
+// that's the one.
+func loadPackage(pkg *packages.Package, path string) (*packages.Package, error) {
+	if path == pkg.PkgPath {
+		return pkg, nil
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedTypes | packages.NeedName | packages.NeedSyntax | packages.NeedImports,
+	}, path)
+	if err != nil {
+		return nil, &PackageLoadError{Package: path, Err: err}
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, &PackageLoadError{Package: path, Err: pkgs[0].Errors[0]}
+	}
+	return pkgs[0], nil
+}
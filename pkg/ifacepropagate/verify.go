@@ -0,0 +1,156 @@
+package ifacepropagate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// maxDiagnostics is how many type errors verify reports at most; the rest
+// tend to be follow-up errors of the first ones.
+const maxDiagnostics = 10
+
+// verify type-checks the generated source together with the rest of the
+// package, so that bugs in the code we generate are reported against the
+// interface and method they came from, rather than as compile errors in the
+// user's package later on.
+//
+// decls are the declarations the source was generated from, in order, and
+// origins says which interface or method some of them were generated for.
+func verify(pkg *packages.Package, sel *structSel, wrapperFuncName, src string, decls []ast.Decl, origins map[ast.Decl]string) error {
+	if len(pkg.Syntax) == 0 {
+		return fmt.Errorf("can't verify the generated code without the syntax of package %q; load it with packages.NeedSyntax, or skip verification", pkg.PkgPath)
+	}
+
+	genFile, err := parser.ParseFile(pkg.Fset, "_ifacepropagate_generated.go", src, 0)
+	if err != nil {
+		return fmt.Errorf("the generated code doesn't parse: %w", err)
+	}
+
+	// The generated file goes last, so that whatever it redeclares is reported
+	// in it, rather than in the package's own files.
+	files := []*ast.File{}
+	paths := map[string]struct{}{}
+	for _, f := range pkg.Syntax {
+		// Skip files which didn't parse, like the empty file the output is
+		// about to be written to, and whatever we generated before, which
+		// this replaces.
+		if f.Name == nil || f.Name.Name != pkg.Name || f == sel.generated {
+			continue
+		}
+		files = append(files, f)
+	}
+	files = append(files, genFile)
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+				paths[path] = struct{}{}
+			}
+		}
+	}
+
+	imports, err := loadImports(paths)
+	if err != nil {
+		return err
+	}
+
+	// The generated declarations follow the imports
+	genDecls := genFile.Decls[len(genFile.Decls)-len(decls):]
+	diags := []string{}
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if imp, ok := imports[path]; ok {
+				return imp, nil
+			}
+			return nil, fmt.Errorf("package %q was not loaded", path)
+		}),
+		Error: func(err error) {
+			terr, ok := err.(types.Error)
+			if !ok {
+				diags = append(diags, err.Error())
+				return
+			}
+			if pkg.Fset.File(terr.Pos) != pkg.Fset.File(genFile.Pos()) {
+				// The package has errors of its own, which aren't ours to report
+				return
+			}
+			origin := "generated code"
+			for i, decl := range genDecls {
+				if decl.Pos() <= terr.Pos && terr.Pos <= decl.End() {
+					origin = declName(decl)
+					if o, ok := origins[decls[i]]; ok {
+						origin = o
+					}
+					break
+				}
+			}
+			diags = append(diags, fmt.Sprintf("%s: %s (at %v)", origin, terr.Msg, pkg.Fset.Position(terr.Pos)))
+		},
+	}
+	// Errors are collected above; the returned one is merely the first of them
+	_, _ = conf.Check(pkg.PkgPath, pkg.Fset, files, nil)
+	if len(diags) > maxDiagnostics {
+		diags = append(diags[:maxDiagnostics], fmt.Sprintf("and %d more", len(diags)-maxDiagnostics))
+	}
+	if len(diags) > 0 {
+		return fmt.Errorf(
+			"the generated code doesn't type-check together with package %q, be it because of what the package declares or a bug in ifacepropagate:\n\t%s",
+			pkg.PkgPath, strings.Join(diags, "\n\t"),
+		)
+	}
+	return nil
+}
+
+// loadImports loads the given packages in one go, so that they agree on the
+// types of the packages they have in common.
+func loadImports(paths map[string]struct{}) (map[string]*types.Package, error) {
+	patterns := make([]string, 0, len(paths))
+	for path := range paths {
+		if path != "unsafe" && path != "C" {
+			patterns = append(patterns, path)
+		}
+	}
+	ret := map[string]*types.Package{}
+	if len(patterns) == 0 {
+		return ret, nil
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedTypes | packages.NeedName | packages.NeedImports,
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("error loading the imports of the generated code: %w", err)
+	}
+	for _, p := range pkgs {
+		if p.Types != nil {
+			ret[p.PkgPath] = p.Types
+		}
+	}
+	return ret, nil
+}
+
+// declName describes a declaration by the name it declares.
+func declName(decl ast.Decl) string {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		return "func " + decl.Name.Name
+	case *ast.GenDecl:
+		if spec, ok := decl.Specs[0].(*ast.TypeSpec); ok {
+			return "type " + spec.Name.Name
+		}
+	}
+	return "generated code"
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
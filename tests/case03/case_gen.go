@@ -0,0 +1,23 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case03
+
+func (p prefixPrinter) propagateInterfaces() Printer {
+	_, ok0 := p.Printer.(Printfer)
+	switch {
+	case ok0:
+		return struct {
+			Printer
+			Printfer
+		}{p, p}
+	case !ok0:
+		return struct {
+			Printer
+		}{p}
+	default:
+		panic("unreachable")
+	}
+}
+func (p prefixPrinter) Printf(format string, args ...interface{}) {
+	p.Printer.(Printfer).Printf(format, args...)
+}
@@ -0,0 +1,33 @@
+package case03
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	lines []string
+}
+
+func (r *recorder) Print(args ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprint(args...))
+}
+
+func (r *recorder) Printf(format string, args ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+func TestVariadic(t *testing.T) {
+	r := &recorder{}
+	p := new(r)
+
+	pf, ok := p.(Printfer)
+	require.True(t, ok)
+
+	p.Print("a", "b")
+	pf.Printf("%s=%d", "x", 1)
+	pf.Printf("no args")
+	require.EqualValues(t, []string{"prefix:ab", "x=1", "no args"}, r.lines)
+}
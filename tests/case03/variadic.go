@@ -0,0 +1,21 @@
+package case03
+
+type Printer interface {
+	Print(args ...interface{})
+}
+
+type Printfer interface {
+	Printf(format string, args ...interface{})
+}
+
+type prefixPrinter struct {
+	Printer
+}
+
+func (p prefixPrinter) Print(args ...interface{}) {
+	p.Printer.Print(append([]interface{}{"prefix:"}, args...)...)
+}
+
+func new(p Printer) Printer {
+	return prefixPrinter{p}.propagateInterfaces()
+}
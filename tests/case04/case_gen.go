@@ -0,0 +1,43 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case04
+
+import (
+	"bytes"
+	"ifacepropagate.testcase/case04/internal/widget"
+	"io"
+	"syscall"
+	"time"
+)
+
+func (w *nameWrapper) propagateInterfaces() Base {
+	_, ok0 := w.Base.(Complex)
+	switch {
+	case ok0:
+		return struct {
+			Base
+			Complex
+		}{w, w}
+	case !ok0:
+		return struct {
+			Base
+		}{w}
+	default:
+		panic("unreachable")
+	}
+}
+func (w *nameWrapper) Buffers(bufs ...*bytes.Buffer) (n int, err error) {
+	return w.Base.(Complex).Buffers(bufs...)
+}
+func (w *nameWrapper) SyscallConn() (c syscall.RawConn, err error) {
+	return w.Base.(Complex).SyscallConn()
+}
+func (w *nameWrapper) Timeouts(c chan<- time.Duration) <-chan time.Duration {
+	return w.Base.(Complex).Timeouts(c)
+}
+func (w *nameWrapper) Visit(f func(io.Reader) error) error {
+	return w.Base.(Complex).Visit(f)
+}
+func (w *nameWrapper) Widgets(m map[string]*widget.Widget) []widget.Widget {
+	return w.Base.(Complex).Widgets(m)
+}
@@ -0,0 +1,58 @@
+package case04
+
+import (
+	"bytes"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"ifacepropagate.testcase/case04/internal/widget"
+)
+
+type impl struct{}
+
+func (impl) Name() string { return "impl" }
+
+func (impl) Widgets(m map[string]*widget.Widget) []widget.Widget {
+	ret := []widget.Widget{}
+	for _, w := range m {
+		ret = append(ret, *w)
+	}
+	return ret
+}
+
+func (impl) Timeouts(c chan<- time.Duration) <-chan time.Duration {
+	ret := make(chan time.Duration, 1)
+	ret <- time.Second
+	return ret
+}
+
+func (impl) Visit(f func(io.Reader) error) error {
+	return f(&bytes.Buffer{})
+}
+
+func (impl) Buffers(bufs ...*bytes.Buffer) (int, error) {
+	return len(bufs), nil
+}
+
+func (impl) SyscallConn() (syscall.RawConn, error) {
+	return nil, nil
+}
+
+func TestSignatures(t *testing.T) {
+	b := new(impl{})
+	require.Equal(t, "wrapped impl", b.Name())
+
+	c, ok := b.(Complex)
+	require.True(t, ok)
+
+	ws := c.Widgets(map[string]*widget.Widget{"a": {Name: "a"}})
+	require.Equal(t, []widget.Widget{{Name: "a"}}, ws)
+	require.Equal(t, time.Second, <-c.Timeouts(nil))
+	require.NoError(t, c.Visit(func(io.Reader) error { return nil }))
+	n, err := c.Buffers(&bytes.Buffer{}, &bytes.Buffer{})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+}
@@ -0,0 +1,5 @@
+package widget
+
+type Widget struct {
+	Name string
+}
@@ -0,0 +1,36 @@
+package case04
+
+import (
+	"bytes"
+	"io"
+	"syscall"
+	"time"
+
+	"ifacepropagate.testcase/case04/internal/widget"
+)
+
+type Base interface {
+	Name() string
+}
+
+// Everything referenced below is only imported by this file, so the
+// generated file has to import it all itself.
+type Complex interface {
+	Widgets(m map[string]*widget.Widget) []widget.Widget
+	Timeouts(c chan<- time.Duration) <-chan time.Duration
+	Visit(f func(io.Reader) error) error
+	Buffers(bufs ...*bytes.Buffer) (n int, err error)
+	SyscallConn() (c syscall.RawConn, err error)
+}
+
+type nameWrapper struct {
+	Base
+}
+
+func (w *nameWrapper) Name() string {
+	return "wrapped " + w.Base.Name()
+}
+
+func new(b Base) Base {
+	return (&nameWrapper{b}).propagateInterfaces()
+}
@@ -0,0 +1,26 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case05
+
+func (w wrapper) propagateInterfaces() Base {
+	_, ok0 := w.Base.(Renamer)
+	switch {
+	case ok0:
+		return struct {
+			Base
+			Renamer
+		}{w, w}
+	case !ok0:
+		return struct {
+			Base
+		}{w}
+	default:
+		panic("unreachable")
+	}
+}
+func (w wrapper) Rename(p0 string, p1 int, p2 bool) (string, error) {
+	return w.Base.(Renamer).Rename(p0, p1, p2)
+}
+func (w wrapper) Touch(p0_ string, p0 int) {
+	w.Base.(Renamer).Touch(p0_, p0)
+}
@@ -0,0 +1,35 @@
+package case05
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type impl struct {
+	touched []string
+}
+
+func (i *impl) Name() string { return "impl" }
+
+func (i *impl) Rename(name string, n int, b bool) (string, error) {
+	return fmt.Sprintf("%s-%d-%v", name, n, b), nil
+}
+
+func (i *impl) Touch(s string, n int) {
+	i.touched = append(i.touched, fmt.Sprintf("%s-%d", s, n))
+}
+
+func TestUnnamed(t *testing.T) {
+	i := &impl{}
+	r, ok := new(i).(Renamer)
+	require.True(t, ok)
+
+	name, err := r.Rename("a", 1, true)
+	require.NoError(t, err)
+	require.Equal(t, "a-1-true", name)
+
+	r.Touch("x", 2)
+	require.Equal(t, []string{"x-2"}, i.touched)
+}
@@ -0,0 +1,19 @@
+package case05
+
+type Base interface {
+	Name() string
+}
+
+type Renamer interface {
+	Rename(string, int, bool) (string, error)
+	// p0 is deliberately named the same as a synthesized name would be
+	Touch(_ string, p0 int)
+}
+
+type wrapper struct {
+	Base
+}
+
+func new(b Base) Base {
+	return wrapper{b}.propagateInterfaces()
+}
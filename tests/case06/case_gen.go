@@ -0,0 +1,46 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case06
+
+import (
+	"ifacepropagate.testcase/case06/shadow"
+	"io"
+)
+
+func (r readWrapper) propagateInterfaces() io.Reader {
+	_, ok0 := r.Reader.(io.ReaderFrom)
+	_, ok1 := r.Reader.(shadow.Shadower)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			io.Reader
+			io.ReaderFrom
+			shadow.Shadower
+		}{r, r, r}
+	case !ok0 && ok1:
+		return struct {
+			io.Reader
+			shadow.Shadower
+		}{r, r}
+	case ok0 && !ok1:
+		return struct {
+			io.Reader
+			io.ReaderFrom
+		}{r, r}
+	case !ok0 && !ok1:
+		return struct {
+			io.Reader
+		}{r}
+	default:
+		panic("unreachable")
+	}
+}
+func (r readWrapper) ReadFrom(r_ io.Reader) (n int64, err error) {
+	return r.Reader.(io.ReaderFrom).ReadFrom(r_)
+}
+func (r readWrapper) Count() (r_ int) {
+	return r.Reader.(shadow.Shadower).Count()
+}
+func (r readWrapper) Shadow(shadow_ string, r_ int, w io.Writer) (r2 int, err error) {
+	return r.Reader.(shadow.Shadower).Shadow(shadow_, r_, w)
+}
@@ -0,0 +1,44 @@
+package case06
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"ifacepropagate.testcase/case06/shadow"
+)
+
+type impl struct {
+	bytes.Buffer
+}
+
+func (i *impl) Shadow(s string, n int, w io.Writer) (int, error) {
+	return io.WriteString(w, strings.Repeat(s, n))
+}
+
+func (i *impl) Count() int {
+	return i.Len()
+}
+
+func TestShadowing(t *testing.T) {
+	i := &impl{}
+	r := new(i)
+
+	rf, ok := r.(io.ReaderFrom)
+	require.True(t, ok)
+	n, err := rf.ReadFrom(strings.NewReader("abc"))
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	s, ok := r.(shadow.Shadower)
+	require.True(t, ok)
+	require.Equal(t, 3, s.Count())
+
+	var out bytes.Buffer
+	written, err := s.Shadow("x", 2, &out)
+	require.NoError(t, err)
+	require.Equal(t, 2, written)
+	require.Equal(t, "xx", out.String())
+}
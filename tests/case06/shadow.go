@@ -0,0 +1,13 @@
+package case06
+
+import (
+	"io"
+)
+
+type readWrapper struct {
+	io.Reader
+}
+
+func new(r io.Reader) io.Reader {
+	return readWrapper{r}.propagateInterfaces()
+}
@@ -0,0 +1,12 @@
+package shadow
+
+import (
+	"io"
+)
+
+// Shadower's params and results collide with the receiver and packages used
+// by the generated code.
+type Shadower interface {
+	Shadow(shadow string, r int, w io.Writer) (r2 int, err error)
+	Count() (r int)
+}
@@ -0,0 +1,7 @@
+package pkg
+
+type Frobulator interface {
+	Frobulate(Knob) Knob
+}
+
+type Knob int
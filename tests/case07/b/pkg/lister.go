@@ -0,0 +1,7 @@
+package pkg
+
+type Lister interface {
+	List() []Item
+}
+
+type Item string
@@ -0,0 +1,44 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case07
+
+import (
+	"fmt"
+	pkg1 "ifacepropagate.testcase/case07/a/pkg"
+	pkg2 "ifacepropagate.testcase/case07/b/pkg"
+)
+
+func (s stringerWrapper) propagateInterfaces() fmt.Stringer {
+	_, ok0 := s.Stringer.(pkg1.Frobulator)
+	_, ok1 := s.Stringer.(pkg2.Lister)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			fmt.Stringer
+			pkg1.Frobulator
+			pkg2.Lister
+		}{s, s, s}
+	case !ok0 && ok1:
+		return struct {
+			fmt.Stringer
+			pkg2.Lister
+		}{s, s}
+	case ok0 && !ok1:
+		return struct {
+			fmt.Stringer
+			pkg1.Frobulator
+		}{s, s}
+	case !ok0 && !ok1:
+		return struct {
+			fmt.Stringer
+		}{s}
+	default:
+		panic("unreachable")
+	}
+}
+func (s stringerWrapper) Frobulate(p0 pkg1.Knob) pkg1.Knob {
+	return s.Stringer.(pkg1.Frobulator).Frobulate(p0)
+}
+func (s stringerWrapper) List() []pkg2.Item {
+	return s.Stringer.(pkg2.Lister).List()
+}
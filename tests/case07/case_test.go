@@ -0,0 +1,30 @@
+package case07
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apkg "ifacepropagate.testcase/case07/a/pkg"
+	bpkg "ifacepropagate.testcase/case07/b/pkg"
+)
+
+type impl struct{}
+
+func (impl) String() string { return "impl" }
+
+func (impl) Frobulate(k apkg.Knob) apkg.Knob { return k + 1 }
+
+func (impl) List() []bpkg.Item { return []bpkg.Item{"a"} }
+
+func TestImportCollisions(t *testing.T) {
+	s := new(impl{})
+	require.Equal(t, "case07: impl", s.String())
+
+	f, ok := s.(apkg.Frobulator)
+	require.True(t, ok)
+	require.Equal(t, apkg.Knob(2), f.Frobulate(1))
+
+	l, ok := s.(bpkg.Lister)
+	require.True(t, ok)
+	require.Equal(t, []bpkg.Item{"a"}, l.List())
+}
@@ -0,0 +1,20 @@
+package case07
+
+import (
+	"fmt"
+)
+
+// pkg clashes with the name of both packages we propagate interfaces from
+const pkg = "case07"
+
+type stringerWrapper struct {
+	fmt.Stringer
+}
+
+func (s stringerWrapper) String() string {
+	return pkg + ": " + s.Stringer.String()
+}
+
+func new(s fmt.Stringer) fmt.Stringer {
+	return stringerWrapper{s}.propagateInterfaces()
+}
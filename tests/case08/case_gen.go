@@ -0,0 +1,42 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case08
+
+import (
+	"io"
+	"net"
+)
+
+type ifacepropagatePartial0 interface {
+	CloseWrite() error
+}
+
+func (c *countingConn) propagateInterfaces() net.Conn {
+	_, ok0 := c.Conn.(io.ReaderFrom)
+	_, ok1 := c.Conn.(HalfCloser)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			net.Conn
+			io.ReaderFrom
+			ifacepropagatePartial0
+		}{c, c, c}
+	case ok0 && !ok1:
+		return struct {
+			net.Conn
+			io.ReaderFrom
+		}{c, c}
+	case !ok0 && !ok1:
+		return struct {
+			net.Conn
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c *countingConn) ReadFrom(r io.Reader) (n int64, err error) {
+	return c.Conn.(io.ReaderFrom).ReadFrom(r)
+}
+func (c *countingConn) CloseWrite() error {
+	return c.Conn.(HalfCloser).CloseWrite()
+}
@@ -0,0 +1,48 @@
+package case08
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlap(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+	tcpConn, err := net.Dial("tcp", srv.Addr().String())
+	require.NoError(t, err)
+	defer tcpConn.Close()
+
+	counting := &countingConn{Conn: tcpConn}
+	conn := counting.propagateInterfaces()
+	// io.ReadWriteCloser is entirely covered by net.Conn, and must not make
+	// any of its methods ambiguous
+	_, ok := conn.(io.ReadWriteCloser)
+	require.True(t, ok)
+	_, ok = conn.(io.ReaderFrom)
+	require.True(t, ok)
+	_, ok = conn.(HalfCloser)
+	require.True(t, ok)
+
+	// Read must still go through our wrapper rather than being forwarded
+	remote, err := srv.Accept()
+	require.NoError(t, err)
+	defer remote.Close()
+	_, err = remote.Write([]byte("x"))
+	require.NoError(t, err)
+	_, err = conn.(io.ReadWriteCloser).Read(make([]byte, 1))
+	require.NoError(t, err)
+	require.Equal(t, 1, counting.reads)
+
+	pipeConn, _ := net.Pipe()
+	conn = new(pipeConn)
+	_, ok = conn.(io.ReadWriteCloser)
+	require.True(t, ok)
+	_, ok = conn.(io.ReaderFrom)
+	require.False(t, ok)
+	_, ok = conn.(HalfCloser)
+	require.False(t, ok)
+}
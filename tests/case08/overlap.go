@@ -0,0 +1,26 @@
+package case08
+
+import (
+	"io"
+	"net"
+)
+
+// HalfCloser overlaps with io.ReaderFrom
+type HalfCloser interface {
+	io.ReaderFrom
+	CloseWrite() error
+}
+
+type countingConn struct {
+	net.Conn
+	reads int
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	c.reads++
+	return c.Conn.Read(b)
+}
+
+func new(c net.Conn) net.Conn {
+	return (&countingConn{Conn: c}).propagateInterfaces()
+}
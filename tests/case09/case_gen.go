@@ -0,0 +1,37 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case09
+
+import "io"
+
+func (w *writerWrapper) propagateInterfaces() io.Writer {
+	_, ok0 := w.Writer.(Flusher)
+	_, ok1 := w.Writer.(StringWriter)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			io.Writer
+			Flusher
+			StringWriter
+		}{w, w, w}
+	case !ok0 && ok1:
+		return struct {
+			io.Writer
+			StringWriter
+		}{w, w}
+	case ok0 && !ok1:
+		return struct {
+			io.Writer
+			Flusher
+		}{w, w}
+	case !ok0 && !ok1:
+		return struct {
+			io.Writer
+		}{w}
+	default:
+		panic("unreachable")
+	}
+}
+func (w *writerWrapper) WriteString(s string) (int, error) {
+	return w.Writer.(StringWriter).WriteString(s)
+}
@@ -0,0 +1,37 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case09
+
+import "io"
+
+func (v valueWrapper) propagateInterfaces() io.Writer {
+	_, ok0 := v.Writer.(Flusher)
+	_, ok1 := v.Writer.(StringWriter)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			io.Writer
+			Flusher
+			StringWriter
+		}{v, v, v}
+	case !ok0 && ok1:
+		return struct {
+			io.Writer
+			StringWriter
+		}{v, v}
+	case ok0 && !ok1:
+		return struct {
+			io.Writer
+			Flusher
+		}{v, v}
+	case !ok0 && !ok1:
+		return struct {
+			io.Writer
+		}{v}
+	default:
+		panic("unreachable")
+	}
+}
+func (v valueWrapper) Flush() error {
+	return v.Writer.(Flusher).Flush()
+}
@@ -0,0 +1,49 @@
+package case09
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type flushingWriter struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushingWriter) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestPromoted(t *testing.T) {
+	inner := &flushingWriter{}
+	w, metrics := newPtr(inner)
+
+	f, ok := w.(Flusher)
+	require.True(t, ok)
+	require.NoError(t, f.Flush())
+	// The promoted Flush was used, rather than one forwarding to inner
+	require.Equal(t, 1, metrics.flushes)
+	require.Equal(t, 0, inner.flushes)
+}
+
+func TestPromotedValue(t *testing.T) {
+	inner := &flushingWriter{}
+	v := valueWrapper{Writer: inner}
+	w := v.propagateInterfaces()
+
+	// Flush is forwarded, as the value receiver doesn't get the promoted one
+	f, ok := w.(Flusher)
+	require.True(t, ok)
+	require.NoError(t, f.Flush())
+	require.Equal(t, 1, inner.flushes)
+
+	// WriteString is promoted from valueMetrics, which ignores it
+	s, ok := w.(StringWriter)
+	require.True(t, ok)
+	_, err := s.WriteString("x")
+	require.NoError(t, err)
+	require.Equal(t, "", inner.String())
+}
@@ -0,0 +1,46 @@
+package case09
+
+import (
+	"io"
+)
+
+type Flusher interface {
+	Flush() error
+}
+
+type StringWriter interface {
+	WriteString(s string) (int, error)
+}
+
+// flushMetrics provides Flush to anything embedding it
+type flushMetrics struct {
+	flushes int
+}
+
+func (f *flushMetrics) Flush() error {
+	f.flushes++
+	return nil
+}
+
+type valueMetrics struct{}
+
+func (valueMetrics) WriteString(s string) (int, error) {
+	return 0, nil
+}
+
+type writerWrapper struct {
+	io.Writer
+	*flushMetrics
+}
+
+type valueWrapper struct {
+	io.Writer
+	// embedded by value, so value receivers get WriteString, but not Flush
+	flushMetrics
+	valueMetrics
+}
+
+func newPtr(w io.Writer) (io.Writer, *flushMetrics) {
+	m := &flushMetrics{}
+	return (&writerWrapper{w, m}).propagateInterfaces(), m
+}
@@ -0,0 +1,23 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case10
+
+func (c *cachedStore) propagateInterfaces() Store {
+	_, ok0 := c.backend.(Deleter)
+	switch {
+	case ok0:
+		return struct {
+			Store
+			Deleter
+		}{c, c}
+	case !ok0:
+		return struct {
+			Store
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c *cachedStore) Delete(k string) {
+	c.backend.(Deleter).Delete(k)
+}
@@ -0,0 +1,31 @@
+package case10
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapStore map[string]string
+
+func (m mapStore) Get(k string) string { return m[k] }
+
+func (m mapStore) Delete(k string) { delete(m, k) }
+
+type getOnly struct{}
+
+func (getOnly) Get(k string) string { return k }
+
+func TestNamedField(t *testing.T) {
+	m := mapStore{"a": "b"}
+	s := new(m)
+	require.Equal(t, "b", s.Get("a"))
+
+	d, ok := s.(Deleter)
+	require.True(t, ok)
+	d.Delete("a")
+	require.Empty(t, m)
+
+	_, ok = new(getOnly{}).(Deleter)
+	require.False(t, ok)
+}
@@ -0,0 +1,28 @@
+package case10
+
+type Store interface {
+	Get(k string) string
+}
+
+type Deleter interface {
+	Delete(k string)
+}
+
+// cachedStore deliberately doesn't embed Store
+type cachedStore struct {
+	backend Store
+	cache   map[string]string
+}
+
+func (c *cachedStore) Get(k string) string {
+	if v, ok := c.cache[k]; ok {
+		return v
+	}
+	v := c.backend.Get(k)
+	c.cache[k] = v
+	return v
+}
+
+func new(s Store) Store {
+	return (&cachedStore{backend: s, cache: map[string]string{}}).propagateInterfaces()
+}
@@ -0,0 +1,25 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case11
+
+import "net/http"
+
+func (s *statusWriter) propagateInterfaces() http.ResponseWriter {
+	_, ok0 := s.base.ResponseWriter.(http.Flusher)
+	switch {
+	case ok0:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+		}{s, s}
+	case !ok0:
+		return struct {
+			http.ResponseWriter
+		}{s}
+	default:
+		panic("unreachable")
+	}
+}
+func (s *statusWriter) Flush() {
+	s.base.ResponseWriter.(http.Flusher).Flush()
+}
@@ -0,0 +1,25 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case11
+
+import "net/http"
+
+func (d deepWriter) propagateInterfaces() http.ResponseWriter {
+	_, ok0 := d.d.inner.w.(http.Flusher)
+	switch {
+	case ok0:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+		}{d, d}
+	case !ok0:
+		return struct {
+			http.ResponseWriter
+		}{d}
+	default:
+		panic("unreachable")
+	}
+}
+func (d deepWriter) Flush() {
+	d.d.inner.w.(http.Flusher).Flush()
+}
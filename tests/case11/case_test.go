@@ -0,0 +1,40 @@
+package case11
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+func TestNestedPath(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w, s := newStatusWriter(rec)
+	w.WriteHeader(http.StatusTeapot)
+	require.Equal(t, http.StatusTeapot, s.status)
+
+	f, ok := w.(http.Flusher)
+	require.True(t, ok)
+	f.Flush()
+	require.True(t, rec.Flushed)
+
+	w, _ = newStatusWriter(plainWriter{rec})
+	_, ok = w.(http.Flusher)
+	require.False(t, ok)
+}
+
+func TestDeepPath(t *testing.T) {
+	rec := httptest.NewRecorder()
+	d := deepWriter{ResponseWriter: rec, d: deeper{inner: &inner{w: rec}}}
+	w := d.propagateInterfaces()
+
+	f, ok := w.(http.Flusher)
+	require.True(t, ok)
+	f.Flush()
+	require.True(t, rec.Flushed)
+}
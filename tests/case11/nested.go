@@ -0,0 +1,37 @@
+package case11
+
+import (
+	"net/http"
+)
+
+type base struct {
+	http.ResponseWriter
+}
+
+type inner struct {
+	w http.ResponseWriter
+}
+
+type deeper struct {
+	inner *inner
+}
+
+type statusWriter struct {
+	base
+	status int
+}
+
+func (s *statusWriter) WriteHeader(status int) {
+	s.status = status
+	s.base.ResponseWriter.WriteHeader(status)
+}
+
+type deepWriter struct {
+	http.ResponseWriter
+	d deeper
+}
+
+func newStatusWriter(w http.ResponseWriter) (http.ResponseWriter, *statusWriter) {
+	s := &statusWriter{base: base{w}}
+	return s.propagateInterfaces(), s
+}
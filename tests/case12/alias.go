@@ -0,0 +1,37 @@
+package case12
+
+import (
+	"io"
+)
+
+type Reader = io.Reader
+
+type Sizer interface {
+	Size() int64
+}
+
+type countingReader struct {
+	Reader
+	n int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.Reader.Read(b)
+	c.n += n
+	return n, err
+}
+
+// The selector refers to the struct by its alias
+type counter = countingReader
+
+type errWrapper struct {
+	error
+}
+
+func (e errWrapper) Error() string {
+	return "wrapped: " + e.error.Error()
+}
+
+type Timeouter interface {
+	Timeout() bool
+}
@@ -0,0 +1,23 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case12
+
+func (c *counter) propagateInterfaces() Reader {
+	_, ok0 := c.Reader.(Sizer)
+	switch {
+	case ok0:
+		return struct {
+			Reader
+			Sizer
+		}{c, c}
+	case !ok0:
+		return struct {
+			Reader
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c *counter) Size() int64 {
+	return c.Reader.(Sizer).Size()
+}
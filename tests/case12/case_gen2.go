@@ -0,0 +1,23 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case12
+
+func (e errWrapper) propagateInterfaces() error {
+	_, ok0 := e.error.(Timeouter)
+	switch {
+	case ok0:
+		return struct {
+			error
+			Timeouter
+		}{e, e}
+	case !ok0:
+		return struct {
+			error
+		}{e}
+	default:
+		panic("unreachable")
+	}
+}
+func (e errWrapper) Timeout() bool {
+	return e.error.(Timeouter).Timeout()
+}
@@ -0,0 +1,42 @@
+package case12
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string { return "timeout" }
+
+func (timeoutErr) Timeout() bool { return true }
+
+func TestAlias(t *testing.T) {
+	c := &counter{Reader: strings.NewReader("abc")}
+	r := c.propagateInterfaces()
+
+	s, ok := r.(Sizer)
+	require.True(t, ok)
+	require.EqualValues(t, 3, s.Size())
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(b))
+	require.Equal(t, 3, c.n)
+}
+
+func TestPredeclared(t *testing.T) {
+	err := errWrapper{timeoutErr{}}.propagateInterfaces()
+	require.Equal(t, "wrapped: timeout", err.Error())
+
+	var timeout interface{ Timeout() bool }
+	require.True(t, errors.As(err, &timeout))
+	require.True(t, timeout.Timeout())
+
+	_, ok := errWrapper{errors.New("x")}.propagateInterfaces().(interface{ Timeout() bool })
+	require.False(t, ok)
+}
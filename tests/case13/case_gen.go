@@ -0,0 +1,39 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case13
+
+import "io"
+
+type ifacepropagatePartial0[K comparable, V Number] interface {
+	Delete(k string)
+}
+
+func (s *tracedStore[K, V]) propagateInterfaces() Store[K, V] {
+	_, ok0 := s.Store.(io.Closer)
+	_, ok1 := s.Store.(CloseDeleter)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			Store[K, V]
+			io.Closer
+			ifacepropagatePartial0[K, V]
+		}{s, s, s}
+	case ok0 && !ok1:
+		return struct {
+			Store[K, V]
+			io.Closer
+		}{s, s}
+	case !ok0 && !ok1:
+		return struct {
+			Store[K, V]
+		}{s}
+	default:
+		panic("unreachable")
+	}
+}
+func (s *tracedStore[K, V]) Close() error {
+	return s.Store.(io.Closer).Close()
+}
+func (s *tracedStore[K, V]) Delete(k string) {
+	s.Store.(CloseDeleter).Delete(k)
+}
@@ -0,0 +1,42 @@
+package case13
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapStore map[string]int
+
+func (m mapStore) Get(k string) (int, bool) {
+	v, ok := m[k]
+	return v, ok
+}
+
+func (m mapStore) Delete(k string) { delete(m, k) }
+
+func (m mapStore) Close() error { return nil }
+
+type getOnly struct{}
+
+func (getOnly) Get(k string) (int, bool) { return 0, false }
+
+func TestGeneric(t *testing.T) {
+	m := mapStore{"a": 1}
+	traced := &tracedStore[string, int]{Store: m}
+	s := traced.propagateInterfaces()
+
+	v, ok := s.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.Equal(t, 1, traced.gets)
+
+	d, ok := s.(CloseDeleter)
+	require.True(t, ok)
+	d.Delete("a")
+	require.Empty(t, m)
+	require.NoError(t, d.Close())
+
+	_, ok = (&tracedStore[string, int]{Store: getOnly{}}).propagateInterfaces().(Deleter)
+	require.False(t, ok)
+}
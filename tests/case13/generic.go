@@ -0,0 +1,33 @@
+package case13
+
+import (
+	"io"
+)
+
+type Store[K comparable, V any] interface {
+	Get(k K) (V, bool)
+}
+
+type Deleter interface {
+	Delete(k string)
+}
+
+// CloseDeleter overlaps with io.Closer
+type CloseDeleter interface {
+	io.Closer
+	Deleter
+}
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+type tracedStore[Key comparable, Val Number] struct {
+	Store[Key, Val]
+	gets int
+}
+
+func (t *tracedStore[Key, Val]) Get(k Key) (Val, bool) {
+	t.gets++
+	return t.Store.Get(k)
+}
@@ -0,0 +1,22 @@
+package cache
+
+type Cache[K comparable, V any] interface {
+	Get(k K) (V, bool)
+}
+
+type Invalidator[K comparable] interface {
+	Invalidate(keys ...K)
+}
+
+type BatchReader[T any] interface {
+	ReadBatch(n int) ([]T, error)
+}
+
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+type Pairer[A, B any] interface {
+	Pairs() []Pair[A, B]
+}
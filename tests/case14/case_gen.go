@@ -0,0 +1,68 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case14
+
+import "ifacepropagate.testcase/case14/cache"
+
+func (e eventCache) propagateInterfaces() cache.Cache[string, Event] {
+	_, ok0 := e.Cache.(cache.Invalidator[string])
+	_, ok1 := e.Cache.(cache.BatchReader[Event])
+	_, ok2 := e.Cache.(cache.Pairer[string, *Event])
+	switch {
+	case ok0 && ok1 && ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.Invalidator[string]
+			cache.BatchReader[Event]
+			cache.Pairer[string, *Event]
+		}{e, e, e, e}
+	case !ok0 && ok1 && ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.BatchReader[Event]
+			cache.Pairer[string, *Event]
+		}{e, e, e}
+	case ok0 && !ok1 && ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.Invalidator[string]
+			cache.Pairer[string, *Event]
+		}{e, e, e}
+	case !ok0 && !ok1 && ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.Pairer[string, *Event]
+		}{e, e}
+	case ok0 && ok1 && !ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.Invalidator[string]
+			cache.BatchReader[Event]
+		}{e, e, e}
+	case !ok0 && ok1 && !ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.BatchReader[Event]
+		}{e, e}
+	case ok0 && !ok1 && !ok2:
+		return struct {
+			cache.Cache[string, Event]
+			cache.Invalidator[string]
+		}{e, e}
+	case !ok0 && !ok1 && !ok2:
+		return struct {
+			cache.Cache[string, Event]
+		}{e}
+	default:
+		panic("unreachable")
+	}
+}
+func (e eventCache) Invalidate(keys ...string) {
+	e.Cache.(cache.Invalidator[string]).Invalidate(keys...)
+}
+func (e eventCache) ReadBatch(n int) ([]Event, error) {
+	return e.Cache.(cache.BatchReader[Event]).ReadBatch(n)
+}
+func (e eventCache) Pairs() []cache.Pair[string, *Event] {
+	return e.Cache.(cache.Pairer[string, *Event]).Pairs()
+}
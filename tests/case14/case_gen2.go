@@ -0,0 +1,25 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case14
+
+import "ifacepropagate.testcase/case14/cache"
+
+func (c typedCache[K]) propagateInterfaces() cache.Cache[K, Event] {
+	_, ok0 := c.Cache.(cache.Invalidator[K])
+	switch {
+	case ok0:
+		return struct {
+			cache.Cache[K, Event]
+			cache.Invalidator[K]
+		}{c, c}
+	case !ok0:
+		return struct {
+			cache.Cache[K, Event]
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c typedCache[K]) Invalidate(keys ...K) {
+	c.Cache.(cache.Invalidator[K]).Invalidate(keys...)
+}
@@ -0,0 +1,72 @@
+package case14
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"ifacepropagate.testcase/case14/cache"
+)
+
+type mapCache struct {
+	m           map[string]Event
+	invalidated []string
+}
+
+func (m *mapCache) Get(k string) (Event, bool) {
+	e, ok := m.m[k]
+	return e, ok
+}
+
+func (m *mapCache) Invalidate(keys ...string) {
+	m.invalidated = append(m.invalidated, keys...)
+}
+
+func (m *mapCache) ReadBatch(n int) ([]Event, error) {
+	return []Event{{Name: "batch"}}, nil
+}
+
+func (m *mapCache) Pairs() []cache.Pair[string, *Event] {
+	return []cache.Pair[string, *Event]{{First: "a", Second: &Event{Name: "a"}}}
+}
+
+type getOnly struct{}
+
+func (getOnly) Get(k string) (Event, bool) { return Event{}, false }
+
+func TestInstantiated(t *testing.T) {
+	m := &mapCache{m: map[string]Event{"a": {Name: "a"}}}
+	c := eventCache{m}.propagateInterfaces()
+
+	e, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "a", e.Name)
+
+	inv, ok := c.(cache.Invalidator[string])
+	require.True(t, ok)
+	inv.Invalidate("a", "b")
+	require.Equal(t, []string{"a", "b"}, m.invalidated)
+
+	br, ok := c.(cache.BatchReader[Event])
+	require.True(t, ok)
+	batch, err := br.ReadBatch(1)
+	require.NoError(t, err)
+	require.Equal(t, []Event{{Name: "batch"}}, batch)
+
+	p, ok := c.(cache.Pairer[string, *Event])
+	require.True(t, ok)
+	require.Len(t, p.Pairs(), 1)
+
+	c = eventCache{getOnly{}}.propagateInterfaces()
+	_, ok = c.(cache.Invalidator[string])
+	require.False(t, ok)
+}
+
+func TestInstantiatedWithTypeParam(t *testing.T) {
+	m := &mapCache{m: map[string]Event{}}
+	c := typedCache[string]{m}.propagateInterfaces()
+
+	inv, ok := c.(cache.Invalidator[string])
+	require.True(t, ok)
+	inv.Invalidate("x")
+	require.Equal(t, []string{"x"}, m.invalidated)
+}
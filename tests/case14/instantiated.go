@@ -0,0 +1,17 @@
+package case14
+
+import (
+	"ifacepropagate.testcase/case14/cache"
+)
+
+type Event struct {
+	Name string
+}
+
+type eventCache struct {
+	cache.Cache[string, Event]
+}
+
+type typedCache[K comparable] struct {
+	cache.Cache[K, Event]
+}
@@ -0,0 +1,50 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case15
+
+import (
+	"net"
+	"time"
+)
+
+type ifacepropagateIface0 interface {
+	CloseWrite() error
+}
+type ifacepropagateIface1 interface {
+	SetKeepAlivePeriod(d time.Duration) error
+}
+
+func (c *closeNotifyingConn) propagateInterfaces() net.Conn {
+	_, ok0 := c.Conn.(ifacepropagateIface0)
+	_, ok1 := c.Conn.(ifacepropagateIface1)
+	switch {
+	case ok0 && ok1:
+		return struct {
+			net.Conn
+			ifacepropagateIface0
+			ifacepropagateIface1
+		}{c, c, c}
+	case !ok0 && ok1:
+		return struct {
+			net.Conn
+			ifacepropagateIface1
+		}{c, c}
+	case ok0 && !ok1:
+		return struct {
+			net.Conn
+			ifacepropagateIface0
+		}{c, c}
+	case !ok0 && !ok1:
+		return struct {
+			net.Conn
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c *closeNotifyingConn) CloseWrite() error {
+	return c.Conn.(ifacepropagateIface0).CloseWrite()
+}
+func (c *closeNotifyingConn) SetKeepAlivePeriod(d time.Duration) error {
+	return c.Conn.(ifacepropagateIface1).SetKeepAlivePeriod(d)
+}
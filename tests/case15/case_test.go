@@ -0,0 +1,35 @@
+package case15
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiterals(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+	tcpConn, err := net.Dial("tcp", srv.Addr().String())
+	require.NoError(t, err)
+
+	conn, closed := new(tcpConn)
+	cw, ok := conn.(interface{ CloseWrite() error })
+	require.True(t, ok)
+	require.NoError(t, cw.CloseWrite())
+
+	_, ok = conn.(interface {
+		SetKeepAlivePeriod(d time.Duration) error
+	})
+	require.True(t, ok)
+
+	require.NoError(t, conn.Close())
+	<-closed
+
+	pipeConn, _ := net.Pipe()
+	conn, _ = new(pipeConn)
+	_, ok = conn.(interface{ CloseWrite() error })
+	require.False(t, ok)
+}
@@ -0,0 +1,20 @@
+package case15
+
+import (
+	"net"
+)
+
+type closeNotifyingConn struct {
+	net.Conn
+	closed chan struct{}
+}
+
+func (c *closeNotifyingConn) Close() error {
+	close(c.closed)
+	return c.Conn.Close()
+}
+
+func new(c net.Conn) (net.Conn, chan struct{}) {
+	closed := make(chan struct{})
+	return (&closeNotifyingConn{c, closed}).propagateInterfaces(), closed
+}
@@ -0,0 +1,81 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case16
+
+import (
+	"io"
+	"net"
+)
+
+type ifacepropagateMethodCloseWrite interface {
+	CloseWrite() error
+}
+type ifacepropagateMethodSetReadBuffer interface {
+	SetReadBuffer(bytes int) error
+}
+type ifacepropagateMethodReadFrom interface {
+	ReadFrom(r io.Reader) (n int64, err error)
+}
+
+func (c *deadlineConn) propagateInterfaces() net.Conn {
+	_, ok0 := c.Conn.(ifacepropagateMethodCloseWrite)
+	_, ok1 := c.Conn.(ifacepropagateMethodSetReadBuffer)
+	_, ok2 := c.Conn.(ifacepropagateMethodReadFrom)
+	switch {
+	case ok0 && ok1 && ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodCloseWrite
+			ifacepropagateMethodSetReadBuffer
+			ifacepropagateMethodReadFrom
+		}{c, c, c, c}
+	case !ok0 && ok1 && ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodSetReadBuffer
+			ifacepropagateMethodReadFrom
+		}{c, c, c}
+	case ok0 && !ok1 && ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodCloseWrite
+			ifacepropagateMethodReadFrom
+		}{c, c, c}
+	case !ok0 && !ok1 && ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodReadFrom
+		}{c, c}
+	case ok0 && ok1 && !ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodCloseWrite
+			ifacepropagateMethodSetReadBuffer
+		}{c, c, c}
+	case !ok0 && ok1 && !ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodSetReadBuffer
+		}{c, c}
+	case ok0 && !ok1 && !ok2:
+		return struct {
+			net.Conn
+			ifacepropagateMethodCloseWrite
+		}{c, c}
+	case !ok0 && !ok1 && !ok2:
+		return struct {
+			net.Conn
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c *deadlineConn) CloseWrite() error {
+	return c.Conn.(ifacepropagateMethodCloseWrite).CloseWrite()
+}
+func (c *deadlineConn) SetReadBuffer(bytes int) error {
+	return c.Conn.(ifacepropagateMethodSetReadBuffer).SetReadBuffer(bytes)
+}
+func (c *deadlineConn) ReadFrom(r io.Reader) (n int64, err error) {
+	return c.Conn.(ifacepropagateMethodReadFrom).ReadFrom(r)
+}
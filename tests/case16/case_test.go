@@ -0,0 +1,42 @@
+package case16
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethods(t *testing.T) {
+	srv, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+	tcpConn, err := net.Dial("tcp", srv.Addr().String())
+	require.NoError(t, err)
+	defer tcpConn.Close()
+
+	conn := new(tcpConn, time.Second)
+	cw, ok := conn.(interface{ CloseWrite() error })
+	require.True(t, ok)
+	require.NoError(t, cw.CloseWrite())
+
+	rb, ok := conn.(interface{ SetReadBuffer(bytes int) error })
+	require.True(t, ok)
+	require.NoError(t, rb.SetReadBuffer(1024))
+
+	_, ok = conn.(io.ReaderFrom)
+	require.True(t, ok)
+
+	// Only the listed methods are propagated, not everything TCPConn has.
+	_, ok = conn.(interface{ SetWriteBuffer(bytes int) error })
+	require.False(t, ok)
+
+	pipeConn, _ := net.Pipe()
+	conn = new(pipeConn, time.Second)
+	_, ok = conn.(interface{ CloseWrite() error })
+	require.False(t, ok)
+	_, ok = conn.(io.ReaderFrom)
+	require.False(t, ok)
+}
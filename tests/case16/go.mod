@@ -0,0 +1,5 @@
+module ifacepropagate.testcase/case16
+
+go 1.15
+
+require github.com/stretchr/testify v1.6.1
@@ -0,0 +1,22 @@
+package case16
+
+import (
+	"net"
+	"time"
+)
+
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+func new(c net.Conn, timeout time.Duration) net.Conn {
+	return (&deadlineConn{c, timeout}).propagateInterfaces()
+}
@@ -0,0 +1,84 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case17
+
+type ifacepropagateIface0 interface {
+	Close() error
+	Flush() error
+	Path() string
+}
+type ifacepropagateIface1 interface {
+	Delete(key string)
+}
+type ifacepropagateIface2 interface {
+	Len() int
+}
+
+func (r readOnlyStore) propagateInterfaces() Store {
+	_, ok0 := r.Store.(ifacepropagateIface0)
+	_, ok1 := r.Store.(ifacepropagateIface1)
+	_, ok2 := r.Store.(ifacepropagateIface2)
+	switch {
+	case ok0 && ok1 && ok2:
+		return struct {
+			Store
+			ifacepropagateIface0
+			ifacepropagateIface1
+			ifacepropagateIface2
+		}{r, r, r, r}
+	case !ok0 && ok1 && ok2:
+		return struct {
+			Store
+			ifacepropagateIface1
+			ifacepropagateIface2
+		}{r, r, r}
+	case ok0 && !ok1 && ok2:
+		return struct {
+			Store
+			ifacepropagateIface0
+			ifacepropagateIface2
+		}{r, r, r}
+	case !ok0 && !ok1 && ok2:
+		return struct {
+			Store
+			ifacepropagateIface2
+		}{r, r}
+	case ok0 && ok1 && !ok2:
+		return struct {
+			Store
+			ifacepropagateIface0
+			ifacepropagateIface1
+		}{r, r, r}
+	case !ok0 && ok1 && !ok2:
+		return struct {
+			Store
+			ifacepropagateIface1
+		}{r, r}
+	case ok0 && !ok1 && !ok2:
+		return struct {
+			Store
+			ifacepropagateIface0
+		}{r, r}
+	case !ok0 && !ok1 && !ok2:
+		return struct {
+			Store
+		}{r}
+	default:
+		panic("unreachable")
+	}
+}
+func (r readOnlyStore) Close() error {
+	return r.Store.(ifacepropagateIface0).Close()
+}
+func (r readOnlyStore) Flush() error {
+	return r.Store.(ifacepropagateIface0).Flush()
+}
+func (r readOnlyStore) Path() string {
+	return r.Store.(ifacepropagateIface0).Path()
+}
+func (r readOnlyStore) Delete(key string) {
+	r.Store.(ifacepropagateIface1).Delete(key)
+}
+func (r readOnlyStore) Len() int {
+	return r.Store.(ifacepropagateIface2).Len()
+}
@@ -0,0 +1,40 @@
+package case17
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type deleter interface{ Delete(key string) }
+
+func TestDerived(t *testing.T) {
+	disk := newReadOnly(&diskStore{path: "/tmp/store", data: map[string]string{"a": "b"}})
+	v, ok := disk.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "b", v)
+
+	_, ok = disk.(deleter)
+	require.True(t, ok)
+	p, ok := disk.(interface{ Path() string })
+	require.True(t, ok)
+	require.Equal(t, "/tmp/store", p.Path())
+	_, ok = disk.(interface{ Flush() error })
+	require.True(t, ok)
+	_, ok = disk.(interface{ Len() int })
+	require.False(t, ok)
+
+	mem := newReadOnly(memStore{"a": "b"})
+	d, ok := mem.(deleter)
+	require.True(t, ok)
+	d.Delete("a")
+	l, ok := mem.(interface{ Len() int })
+	require.True(t, ok)
+	require.Equal(t, 0, l.Len())
+	_, ok = mem.(interface{ Path() string })
+	require.False(t, ok)
+
+	// Reset was excluded
+	_, ok = mem.(interface{ Reset() })
+	require.False(t, ok)
+}
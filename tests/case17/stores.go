@@ -0,0 +1,48 @@
+package case17
+
+type Store interface {
+	Get(key string) (string, bool)
+}
+
+type diskStore struct {
+	path string
+	data map[string]string
+}
+
+func (d *diskStore) Get(key string) (string, bool) {
+	v, ok := d.data[key]
+	return v, ok
+}
+
+func (d *diskStore) Delete(key string) { delete(d.data, key) }
+
+func (d *diskStore) Flush() error { return nil }
+
+func (d *diskStore) Close() error { return nil }
+
+func (d *diskStore) Path() string { return d.path }
+
+type memStore map[string]string
+
+func (m memStore) Get(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m memStore) Delete(key string) { delete(m, key) }
+
+func (m memStore) Len() int { return len(m) }
+
+func (m memStore) Reset() {
+	for k := range m {
+		delete(m, k)
+	}
+}
+
+type readOnlyStore struct {
+	Store
+}
+
+func newReadOnly(s Store) Store {
+	return readOnlyStore{s}.propagateInterfaces()
+}
@@ -0,0 +1,48 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case18
+
+import "io"
+
+type ifacepropagatePartial0 interface {
+	Close() error
+}
+type ifacepropagatePartial1 interface {
+	Seek(offset int64, whence int) (int64, error)
+}
+
+func (c *countingReader) propagateInterfaces() io.Reader {
+	_, ok0 := c.Reader.(io.ReadCloser)
+	_, ok1 := c.Reader.(io.ReadSeeker)
+	_, ok2 := c.Reader.(io.ReadSeekCloser)
+	switch {
+	case ok0 && ok1 && ok2:
+		return struct {
+			io.Reader
+			ifacepropagatePartial0
+			ifacepropagatePartial1
+		}{c, c, c}
+	case !ok0 && ok1 && !ok2:
+		return struct {
+			io.Reader
+			ifacepropagatePartial1
+		}{c, c}
+	case ok0 && !ok1 && !ok2:
+		return struct {
+			io.Reader
+			ifacepropagatePartial0
+		}{c, c}
+	case !ok0 && !ok1 && !ok2:
+		return struct {
+			io.Reader
+		}{c}
+	default:
+		panic("unreachable")
+	}
+}
+func (c *countingReader) Close() error {
+	return c.Reader.(io.ReadCloser).Close()
+}
+func (c *countingReader) Seek(offset int64, whence int) (int64, error) {
+	return c.Reader.(io.ReadSeeker).Seek(offset, whence)
+}
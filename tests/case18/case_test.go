@@ -0,0 +1,38 @@
+package case18
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type readCloser struct {
+	io.Reader
+}
+
+func (readCloser) Close() error { return nil }
+
+func TestPruned(t *testing.T) {
+	r := newCounting(strings.NewReader("abc"))
+	_, ok := r.(io.ReadSeeker)
+	require.True(t, ok)
+	_, ok = r.(io.Closer)
+	require.False(t, ok)
+
+	r = newCounting(readCloser{&bytes.Buffer{}})
+	_, ok = r.(io.ReadCloser)
+	require.True(t, ok)
+	_, ok = r.(io.Seeker)
+	require.False(t, ok)
+
+	f, err := os.Open("case_test.go")
+	require.NoError(t, err)
+	r = newCounting(f)
+	_, ok = r.(io.ReadSeekCloser)
+	require.True(t, ok)
+	require.NoError(t, r.(io.Closer).Close())
+}
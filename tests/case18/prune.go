@@ -0,0 +1,21 @@
+package case18
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+func newCounting(r io.Reader) io.Reader {
+	return (&countingReader{Reader: r}).propagateInterfaces()
+}
@@ -0,0 +1,63 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case19
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+func (s *statusRecorder) propagateInterfaces() http.ResponseWriter {
+	_, ok0 := s.ResponseWriter.(http.Flusher)
+	_, ok1 := s.ResponseWriter.(http.Pusher)
+	_, ok2 := s.ResponseWriter.(http.Hijacker)
+	switch {
+	case ok0 && ok1 && ok2:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+			http.Hijacker
+		}{s, s, s, s}
+	case ok0 && !ok1 && ok2:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{s, s, s}
+	case !ok0 && !ok1 && ok2:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+		}{s, s}
+	case ok0 && ok1 && !ok2:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+		}{s, s, s}
+	case ok0 && !ok1 && !ok2:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+		}{s, s}
+	case !ok0 && !ok1 && !ok2:
+		return struct {
+			http.ResponseWriter
+		}{s}
+	default:
+		return struct {
+			http.ResponseWriter
+		}{s}
+	}
+}
+func (s *statusRecorder) Flush() {
+	s.ResponseWriter.(http.Flusher).Flush()
+}
+func (s *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	return s.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return s.ResponseWriter.(http.Hijacker).Hijack()
+}
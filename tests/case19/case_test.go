@@ -0,0 +1,48 @@
+package case19
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pushFlushWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (pushFlushWriter) Push(string, *http.PushOptions) error { return nil }
+
+// pushHijackWriter breaks the rule that Pushers are Flushers
+type pushHijackWriter struct {
+	http.ResponseWriter
+}
+
+func (pushHijackWriter) Push(string, *http.PushOptions) error { return nil }
+
+func (pushHijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return nil, nil, nil }
+
+func TestImplies(t *testing.T) {
+	w := record(httptest.NewRecorder())
+	_, ok := w.(http.Flusher)
+	require.True(t, ok)
+	_, ok = w.(http.Pusher)
+	require.False(t, ok)
+
+	w = record(pushFlushWriter{httptest.NewRecorder()})
+	_, ok = w.(http.Flusher)
+	require.True(t, ok)
+	_, ok = w.(http.Pusher)
+	require.True(t, ok)
+
+	// Only the base interface is left for values breaking the rule
+	w = record(pushHijackWriter{httptest.NewRecorder()})
+	_, ok = w.(http.Pusher)
+	require.False(t, ok)
+	_, ok = w.(http.Hijacker)
+	require.False(t, ok)
+	w.WriteHeader(http.StatusTeapot)
+}
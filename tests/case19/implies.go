@@ -0,0 +1,19 @@
+package case19
+
+import (
+	"net/http"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func record(w http.ResponseWriter) http.ResponseWriter {
+	return (&statusRecorder{ResponseWriter: w}).propagateInterfaces()
+}
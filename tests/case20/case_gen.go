@@ -0,0 +1,43 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case20
+
+import "io"
+
+func (l loggedStore) propagateInterfaces() Store {
+	_, ok0 := l.Store.(Deleter)
+	_, ok1 := l.Store.(Flusher)
+	_, ok2 := l.Store.(io.Closer)
+	_, ok3 := l.Store.(Lister)
+	switch {
+	case ok0 && !ok1 && !ok2 && ok3:
+		return struct {
+			Store
+			Deleter
+			Lister
+		}{l, l, l}
+	case ok0 && ok1 && ok2 && !ok3:
+		return struct {
+			Store
+			Deleter
+			Flusher
+			io.Closer
+		}{l, l, l, l}
+	default:
+		return struct {
+			Store
+		}{l}
+	}
+}
+func (l loggedStore) Delete(key string) {
+	l.Store.(Deleter).Delete(key)
+}
+func (l loggedStore) Flush() error {
+	return l.Store.(Flusher).Flush()
+}
+func (l loggedStore) Close() error {
+	return l.Store.(io.Closer).Close()
+}
+func (l loggedStore) List() []string {
+	return l.Store.(Lister).List()
+}
@@ -0,0 +1,43 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case20
+
+import "io"
+
+func (t tracedStore) propagateInterfaces() Store {
+	_, ok0 := t.Store.(Deleter)
+	_, ok1 := t.Store.(Flusher)
+	_, ok2 := t.Store.(io.Closer)
+	_, ok3 := t.Store.(Lister)
+	switch {
+	case ok0 && ok1 && ok2:
+		return struct {
+			Store
+			Deleter
+			Flusher
+			io.Closer
+		}{t, t, t, t}
+	case ok0 && ok3:
+		return struct {
+			Store
+			Deleter
+			Lister
+		}{t, t, t}
+	default:
+		return struct {
+			Store
+		}{t}
+	}
+}
+func (t tracedStore) Delete(key string) {
+	t.Store.(Deleter).Delete(key)
+}
+func (t tracedStore) Flush() error {
+	return t.Store.(Flusher).Flush()
+}
+func (t tracedStore) Close() error {
+	return t.Store.(io.Closer).Close()
+}
+func (t tracedStore) List() []string {
+	return t.Store.(Lister).List()
+}
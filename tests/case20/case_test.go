@@ -0,0 +1,53 @@
+package case20
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unknownStore is neither of the types the stores were generated for
+type unknownStore struct {
+	memStore
+}
+
+func (unknownStore) Close() error { return nil }
+
+func TestRealized(t *testing.T) {
+	for _, wrap := range []func(Store) Store{newLogged, newTraced} {
+		s := wrap(&diskStore{})
+		_, ok := s.(Deleter)
+		require.True(t, ok)
+		_, ok = s.(Flusher)
+		require.True(t, ok)
+		_, ok = s.(io.Closer)
+		require.True(t, ok)
+		_, ok = s.(Lister)
+		require.False(t, ok)
+
+		s = wrap(memStore{})
+		_, ok = s.(Deleter)
+		require.True(t, ok)
+		_, ok = s.(Lister)
+		require.True(t, ok)
+		_, ok = s.(io.Closer)
+		require.False(t, ok)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	s := newLogged(unknownStore{memStore{}})
+	_, ok := s.(Deleter)
+	require.False(t, ok)
+	_, ok = s.(Lister)
+	require.False(t, ok)
+
+	s = newTraced(unknownStore{memStore{}})
+	_, ok = s.(Deleter)
+	require.True(t, ok)
+	_, ok = s.(Lister)
+	require.True(t, ok)
+	_, ok = s.(io.Closer)
+	require.False(t, ok)
+}
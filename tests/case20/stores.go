@@ -0,0 +1,65 @@
+package case20
+
+type Store interface {
+	Get(key string) (string, bool)
+}
+
+type Deleter interface {
+	Delete(key string)
+}
+
+type Flusher interface {
+	Flush() error
+}
+
+type Lister interface {
+	List() []string
+}
+
+type diskStore struct {
+	data map[string]string
+}
+
+func (d *diskStore) Get(key string) (string, bool) {
+	v, ok := d.data[key]
+	return v, ok
+}
+
+func (d *diskStore) Delete(key string) { delete(d.data, key) }
+
+func (d *diskStore) Flush() error { return nil }
+
+func (d *diskStore) Close() error { return nil }
+
+type memStore map[string]string
+
+func (m memStore) Get(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m memStore) Delete(key string) { delete(m, key) }
+
+func (m memStore) List() []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type loggedStore struct {
+	Store
+}
+
+type tracedStore struct {
+	Store
+}
+
+func newLogged(s Store) Store {
+	return loggedStore{s}.propagateInterfaces()
+}
+
+func newTraced(s Store) Store {
+	return tracedStore{s}.propagateInterfaces()
+}
@@ -0,0 +1,40 @@
+package case21
+
+import (
+	"io"
+)
+
+type Getter[T any] interface {
+	Get() T
+}
+
+// GA is a generic alias, which the generated code has to instantiate
+type GA[T any] = Getter[T]
+
+type Pair[K comparable, V any] = map[K]V
+
+type Gimmer interface {
+	Gimme() GA[int]
+	Pairs() Pair[string, GA[string]]
+}
+
+type loggedReader struct {
+	io.Reader
+	reads int
+}
+
+func (l *loggedReader) Read(b []byte) (int, error) {
+	l.reads++
+	return l.Reader.Read(b)
+}
+
+// aliasWrap wraps an instantiated generic alias
+type aliasWrap struct {
+	GA[int]
+	gets int
+}
+
+func (a *aliasWrap) Get() int {
+	a.gets++
+	return a.GA.Get()
+}
@@ -0,0 +1,28 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case21
+
+import "io"
+
+func (l *loggedReader) propagateInterfaces() io.Reader {
+	_, ok0 := l.Reader.(Gimmer)
+	switch {
+	case ok0:
+		return struct {
+			io.Reader
+			Gimmer
+		}{l, l}
+	case !ok0:
+		return struct {
+			io.Reader
+		}{l}
+	default:
+		panic("unreachable")
+	}
+}
+func (l *loggedReader) Gimme() GA[int] {
+	return l.Reader.(Gimmer).Gimme()
+}
+func (l *loggedReader) Pairs() Pair[string, GA[string]] {
+	return l.Reader.(Gimmer).Pairs()
+}
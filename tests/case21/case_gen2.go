@@ -0,0 +1,25 @@
+// Code generated by github.com/euank/ifacepropagate
+
+package case21
+
+import "io"
+
+func (a *aliasWrap) propagateInterfaces() GA[int] {
+	_, ok0 := a.GA.(io.Closer)
+	switch {
+	case ok0:
+		return struct {
+			GA[int]
+			io.Closer
+		}{a, a}
+	case !ok0:
+		return struct {
+			GA[int]
+		}{a}
+	default:
+		panic("unreachable")
+	}
+}
+func (a *aliasWrap) Close() error {
+	return a.GA.(io.Closer).Close()
+}
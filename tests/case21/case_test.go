@@ -0,0 +1,65 @@
+package case21
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type intGetter int
+
+func (i intGetter) Get() int { return int(i) }
+
+type stringGetter string
+
+func (s stringGetter) Get() string { return string(s) }
+
+type gimmeReader struct {
+	io.Reader
+}
+
+func (gimmeReader) Gimme() GA[int] { return intGetter(42) }
+
+func (gimmeReader) Pairs() Pair[string, GA[string]] {
+	return Pair[string, GA[string]]{"a": stringGetter("b")}
+}
+
+func TestGenericAlias(t *testing.T) {
+	l := &loggedReader{Reader: gimmeReader{strings.NewReader("abc")}}
+	r := l.propagateInterfaces()
+
+	g, ok := r.(Gimmer)
+	require.True(t, ok)
+	require.Equal(t, 42, g.Gimme().Get())
+	require.Equal(t, "b", g.Pairs()["a"].Get())
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(b))
+	require.Equal(t, 2, l.reads)
+
+	_, ok = (&loggedReader{Reader: strings.NewReader("")}).propagateInterfaces().(Gimmer)
+	require.False(t, ok)
+}
+
+type closingGetter int
+
+func (c closingGetter) Get() int { return int(c) }
+
+func (closingGetter) Close() error { return nil }
+
+func TestGenericAliasField(t *testing.T) {
+	a := &aliasWrap{GA: closingGetter(7)}
+	g := a.propagateInterfaces()
+
+	require.Equal(t, 7, g.Get())
+	require.Equal(t, 1, a.gets)
+	c, ok := g.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, c.Close())
+
+	_, ok = (&aliasWrap{GA: intGetter(1)}).propagateInterfaces().(io.Closer)
+	require.False(t, ok)
+}